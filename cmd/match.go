@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/app/test"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	"github.com/spf13/cobra"
+)
+
+type _matchFlags struct {
+	engines     []string
+	tc          string
+	movetime    int
+	depth       int
+	games       int
+	concurrency int
+	openings    string
+	hash        int
+	threads     int
+	options     []string
+	config      string
+}
+
+var matchFlags _matchFlags
+
+var matchCmd = &cobra.Command{
+	Use:   "match",
+	Short: "Play a local match between two engines",
+	Long: "Plays games between two engines on this machine without connecting to the test server.\n" +
+		"Each engine is either the path to a binary or the name and version of an installed engine (name@version).\n" +
+		"Every opening is played twice with swapped colors. Without an opening file the standard starting position is used.\n" +
+		"The result of each game and the running score are printed to the console.\n",
+
+	Run: func(cmd *cobra.Command, args []string) {
+		conf.Load(matchFlags.config)
+
+		if len(matchFlags.engines) != 2 {
+			fmt.Println("Error: exactly two engines are required")
+			os.Exit(1)
+		}
+
+		if matchFlags.games < 1 {
+			fmt.Println("Error: at least one game is required")
+			os.Exit(1)
+		}
+
+		err := test.RunMatch(test.MatchConfig{
+			Engines:     [2]string{matchFlags.engines[0], matchFlags.engines[1]},
+			TimeControl: matchFlags.tc,
+			MoveTime:    matchFlags.movetime,
+			Depth:       matchFlags.depth,
+			Games:       matchFlags.games,
+			Concurrency: matchFlags.concurrency,
+			Openings:    matchFlags.openings,
+			Hash:        matchFlags.hash,
+			Threads:     matchFlags.threads,
+			Options:     matchFlags.options,
+		})
+
+		if err != nil {
+			fmt.Println("Error running match: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(matchCmd)
+	matchCmd.Flags().StringArrayVarP(&matchFlags.engines, "engine", "e", nil, "Engine binary or name@version of an installed engine (use twice)")
+	matchCmd.Flags().StringVarP(&matchFlags.tc, "tc", "t", "10+0.1", "Time control in the format [moves/]seconds[+increment]")
+	matchCmd.Flags().IntVar(&matchFlags.movetime, "movetime", 0, "Fixed time per move in ms (replaces the time control)")
+	matchCmd.Flags().IntVar(&matchFlags.depth, "depth", 0, "Fixed depth per move (replaces the time control)")
+	matchCmd.Flags().IntVarP(&matchFlags.games, "games", "n", 2, "Number of games to play")
+	matchCmd.Flags().IntVarP(&matchFlags.concurrency, "concurrency", "j", 0, "Number of games to play at the same time (derived from the hardware by default)")
+	matchCmd.Flags().StringVarP(&matchFlags.openings, "openings", "o", "", "Path to a file with one FEN or EPD per line")
+	matchCmd.Flags().IntVar(&matchFlags.hash, "hash", 16, "Hash size in MB for both engines")
+	matchCmd.Flags().IntVar(&matchFlags.threads, "threads", 1, "Number of threads for both engines")
+	matchCmd.Flags().StringArrayVar(&matchFlags.options, "option", nil, "Custom option of one engine in the format engine:name=value, where engine is the number of the engine starting at 1 (repeatable)")
+	matchCmd.Flags().StringVarP(&matchFlags.config, "config", "c", "", "The path to the configuration file")
+
+	matchCmd.MarkFlagRequired("engine")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/app/human"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	"github.com/spf13/cobra"
+)
+
+type _playFlags struct {
+	human  bool
+	player string
+	config string
+}
+
+var playFlags _playFlags
+
+var playCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Plays a game on the game server yourself",
+	Long: "Connects to the game server with the given player ID and lets you play the game in the terminal.\n" +
+		"The board, the clocks and the history of the game are shown while playing.\n" +
+		"When it is your turn, type a move in UCI (e2e4) or standard algebraic notation (e4, Nf3, O-O) and press enter.\n" +
+		"Illegal moves are rejected before they are sent to the server.\n" +
+		"Press ctrl+c or esc to leave the game and q to quit after the result was shown.\n" +
+		"Only human players are supported. Use the run command to let an engine play.\n",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !playFlags.human {
+			fmt.Println("Error: only human players are supported, use --human or the run command")
+			os.Exit(1)
+		}
+
+		conf.Load(playFlags.config)
+
+		if err := human.Play(playFlags.player); err != nil {
+			fmt.Println("Error playing game: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(playCmd)
+	playCmd.Flags().BoolVar(&playFlags.human, "human", false, "Play the game yourself")
+	playCmd.Flags().StringVarP(&playFlags.player, "player", "p", "", "Player ID")
+	playCmd.Flags().StringVarP(&playFlags.config, "config", "c", "", "The path to the configuration file")
+	playCmd.MarkFlagRequired("player")
+}
@@ -10,11 +10,14 @@ import (
 )
 
 type _runFlags struct {
-	player  string
+	players []string
+	file    string
 	exe     string
 	engine  string
 	version string
 	config  string
+	stay    bool
+	tui     bool
 }
 
 var runFlags _runFlags
@@ -25,39 +28,73 @@ var runCmd = &cobra.Command{
 	Long: "Launches the engine given by name and version or path.\n" +
 		"A connection against the game host server will be created using the given player ID.\n" +
 		"Once the game server sends a move request, the engine will be invoked to calculate a move.\n" +
-		"The output from the engine and all prompts to the engine will be printed to the console.\n",
+		"The output from the engine and all prompts to the engine will be printed to the console.\n" +
+		"Several player IDs can be given by repeating --player or in a file with one ID per line.\n" +
+		"Each player gets its own connection and engine process and its output is prefixed with the player ID.\n" +
+		"Once the game is over the result is printed and the command exits, unless --stay is set.\n" +
+		"With --stay the player checks in for the next game with the same player ID.\n" +
+		"If the engine crashes or stops responding, it is restarted and searches the position again with the time left.\n" +
+		"The number of restarts per game is limited by engine-restarts in the configuration file.\n" +
+		"With --tui a single player is shown in a terminal user interface with the board, the evaluation\n" +
+		"and principal variation of the engine, the clocks and the connection. The raw log can be scrolled.\n" +
+		"The exit status is 0 if all games finished and 1 if a connection dropped or the server reported an error.\n",
 
 	Run: func(cmd *cobra.Command, args []string) {
 		conf.Load(runFlags.config)
 
-		ifc, stdin, stdout, err := run.SetupEngineIfc(runFlags.exe, runFlags.engine, runFlags.version)
+		ids := runFlags.players
+
+		if runFlags.file != "" {
+			fileIds, err := run.LoadPlayers(runFlags.file)
+
+			if err != nil {
+				fmt.Println("Error reading player IDs: ", err)
+				os.Exit(1)
+			}
+
+			ids = append(ids, fileIds...)
+		}
+
+		if len(ids) == 0 {
+			fmt.Println("Error: at least one player ID is required")
+			os.Exit(1)
+		}
+
+		if runFlags.tui && len(ids) > 1 {
+			fmt.Println("Error: the terminal user interface supports a single player ID")
+			os.Exit(1)
+		}
+
+		exe, err := run.ResolveEngine(runFlags.exe, runFlags.engine, runFlags.version)
 
 		if err != nil {
 			fmt.Println("Error setting up engine interface: ", err)
 			os.Exit(1)
 		}
 
-		pipe := func(c chan string, prefix string) {
-			for s := range c {
-				fmt.Println(prefix + s)
-			}
+		if runFlags.tui {
+			err = run.PlayView(exe, ids[0], runFlags.stay)
+		} else {
+			err = run.PlayAll(exe, ids, runFlags.stay)
 		}
 
-		go pipe(stdin, "> ")
-		go pipe(stdout, "")
+		if err != nil {
+			fmt.Println("Error playing game: ", err)
+			os.Exit(1)
+		}
 
-		run.Play(ifc, runFlags.player)
 		os.Exit(0)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
-	runCmd.Flags().StringVarP(&runFlags.player, "player", "p", "", "Player ID")
+	runCmd.Flags().StringArrayVarP(&runFlags.players, "player", "p", nil, "Player ID (can be repeated)")
+	runCmd.Flags().StringVar(&runFlags.file, "players", "", "Path to a file with one player ID per line")
 	runCmd.Flags().StringVarP(&runFlags.exe, "binary", "b", "", "Path to engine executable")
 	runCmd.Flags().StringVarP(&runFlags.engine, "engine", "e", "", "Engine Name (must be installed)")
 	runCmd.Flags().StringVarP(&runFlags.version, "version", "v", "", "Version of Engine (must be installed)")
 	runCmd.Flags().StringVarP(&runFlags.config, "config", "c", "", "The path to the configuration file")
-
-	runCmd.MarkFlagRequired("player")
+	runCmd.Flags().BoolVar(&runFlags.tui, "tui", false, "Show the game in a terminal user interface instead of printing the raw log")
+	runCmd.Flags().BoolVar(&runFlags.stay, "stay", false, "Stay connected and play the next game with the same player ID")
 }
@@ -8,10 +8,16 @@ import (
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 type _testFlags struct {
-	config string
+	config       string
+	headless     bool
+	concurrency  int
+	maxCores     int
+	maxMemory    int
+	reserveCores int
 }
 
 var testFlags _testFlags
@@ -23,9 +29,39 @@ var testCmd = &cobra.Command{
 		"The programm will report the system stats to the test server and wait until the server requests a test to run.\n" +
 		"A test will download the requested engines and play a batch of games.\n" +
 		"The number of games played depends on the number of cores and memory available on the system.\n" +
-		"Use q or ctrl+c to exit at any time.",
+		"The resources used for games can be limited with the flags below or the resources section of the configuration file.\n" +
+		"Press q or ctrl+c to stop scheduling new games, report the running ones and exit.\n" +
+		"Press it a second time to exit immediately.\n" +
+		"When stdout is not a terminal or --headless is set, progress is written as log lines instead.\n" +
+		"In this mode SIGINT or SIGTERM drains the running games in the same way.",
 	Run: func(cmd *cobra.Command, args []string) {
 		conf.Load(testFlags.config)
+		res := conf.GetResourceConfig()
+
+		if cmd.Flags().Changed("concurrency") {
+			res.Concurrency = testFlags.concurrency
+		}
+
+		if cmd.Flags().Changed("max-cores") {
+			res.MaxCores = testFlags.maxCores
+		}
+
+		if cmd.Flags().Changed("max-memory") {
+			res.MaxMemory = testFlags.maxMemory
+		}
+
+		if cmd.Flags().Changed("reserve-cores") {
+			res.ReserveCores = testFlags.reserveCores
+		}
+
+		if testFlags.headless || !term.IsTerminal(int(os.Stdout.Fd())) {
+			if err := test.RunHeadless(); err != nil {
+				os.Exit(1)
+			}
+
+			return
+		}
+
 		model := test.BuildTestViewModel()
 
 		if _, err := tea.NewProgram(model).Run(); err != nil {
@@ -38,4 +74,9 @@ var testCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(testCmd)
 	testCmd.Flags().StringVarP(&testFlags.config, "config", "c", "", "The path to the configuration file")
+	testCmd.Flags().BoolVar(&testFlags.headless, "headless", false, "Run without the terminal user interface")
+	testCmd.Flags().IntVarP(&testFlags.concurrency, "concurrency", "j", 0, "Fixed number of games to play at the same time (derived from the resources by default)")
+	testCmd.Flags().IntVar(&testFlags.maxCores, "max-cores", 0, "Maximum number of cores used for games (0 for all)")
+	testCmd.Flags().IntVar(&testFlags.maxMemory, "max-memory", 0, "Maximum memory in MB used for games (0 for all)")
+	testCmd.Flags().IntVar(&testFlags.reserveCores, "reserve-cores", 0, "Number of cores kept free for other programs")
 }
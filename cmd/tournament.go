@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/app/test"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	"github.com/spf13/cobra"
+)
+
+type _tournamentFlags struct {
+	engines     []string
+	format      string
+	rounds      int
+	tc          string
+	movetime    int
+	depth       int
+	concurrency int
+	openings    string
+	hash        int
+	threads     int
+	options     []string
+	pgn         string
+	json        string
+	config      string
+}
+
+var tournamentFlags _tournamentFlags
+
+var tournamentCmd = &cobra.Command{
+	Use:   "tournament",
+	Short: "Play a local tournament between several engines",
+	Long: "Plays a round-robin or gauntlet tournament on this machine without connecting to the test server.\n" +
+		"Each engine is either the path to a binary or the name and version of an installed engine (name@version).\n" +
+		"In a round-robin tournament every engine plays against every other engine.\n" +
+		"In a gauntlet tournament the first engine plays against every other engine.\n" +
+		"Each round every pairing plays one opening twice with swapped colors.\n" +
+		"The final crosstable is printed to the console and can be saved as JSON together with a PGN of all games.\n",
+
+	Run: func(cmd *cobra.Command, args []string) {
+		conf.Load(tournamentFlags.config)
+
+		if tournamentFlags.rounds < 1 {
+			fmt.Println("Error: at least one round is required")
+			os.Exit(1)
+		}
+
+		err := test.RunTournament(test.TournamentConfig{
+			Engines:     tournamentFlags.engines,
+			Format:      test.TournamentFormat(tournamentFlags.format),
+			Rounds:      tournamentFlags.rounds,
+			TimeControl: tournamentFlags.tc,
+			MoveTime:    tournamentFlags.movetime,
+			Depth:       tournamentFlags.depth,
+			Concurrency: tournamentFlags.concurrency,
+			Openings:    tournamentFlags.openings,
+			Hash:        tournamentFlags.hash,
+			Threads:     tournamentFlags.threads,
+			Options:     tournamentFlags.options,
+			PGN:         tournamentFlags.pgn,
+			JSON:        tournamentFlags.json,
+		})
+
+		if err != nil {
+			fmt.Println("Error running tournament: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tournamentCmd)
+	tournamentCmd.Flags().StringArrayVarP(&tournamentFlags.engines, "engine", "e", nil, "Engine binary or name@version of an installed engine (repeat for each engine)")
+	tournamentCmd.Flags().StringVarP(&tournamentFlags.format, "format", "f", string(test.RoundRobin), "Tournament format (round-robin or gauntlet)")
+	tournamentCmd.Flags().IntVarP(&tournamentFlags.rounds, "rounds", "r", 1, "Number of game pairs played by each pairing")
+	tournamentCmd.Flags().StringVarP(&tournamentFlags.tc, "tc", "t", "10+0.1", "Time control in the format [moves/]seconds[+increment]")
+	tournamentCmd.Flags().IntVar(&tournamentFlags.movetime, "movetime", 0, "Fixed time per move in ms (replaces the time control)")
+	tournamentCmd.Flags().IntVar(&tournamentFlags.depth, "depth", 0, "Fixed depth per move (replaces the time control)")
+	tournamentCmd.Flags().IntVarP(&tournamentFlags.concurrency, "concurrency", "j", 0, "Number of games to play at the same time (derived from the hardware by default)")
+	tournamentCmd.Flags().StringVarP(&tournamentFlags.openings, "openings", "o", "", "Path to a file with one FEN or EPD per line")
+	tournamentCmd.Flags().IntVar(&tournamentFlags.hash, "hash", 16, "Hash size in MB for all engines")
+	tournamentCmd.Flags().IntVar(&tournamentFlags.threads, "threads", 1, "Number of threads for all engines")
+	tournamentCmd.Flags().StringVar(&tournamentFlags.pgn, "pgn", "", "Path of the PGN file to write all games to")
+	tournamentCmd.Flags().StringVar(&tournamentFlags.json, "json", "", "Path of the JSON file to write the crosstable to")
+	tournamentCmd.Flags().StringArrayVar(&tournamentFlags.options, "option", nil, "Custom option of one engine in the format engine:name=value, where engine is the number of the engine starting at 1 (repeatable)")
+	tournamentCmd.Flags().StringVarP(&tournamentFlags.config, "config", "c", "", "The path to the configuration file")
+
+	tournamentCmd.MarkFlagRequired("engine")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/app/test"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	"github.com/spf13/cobra"
+)
+
+type _tuneFlags struct {
+	engine      string
+	params      string
+	iterations  int
+	checkpoint  string
+	resume      bool
+	tc          string
+	movetime    int
+	depth       int
+	concurrency int
+	openings    string
+	hash        int
+	threads     int
+	config      string
+}
+
+var tuneFlags _tuneFlags
+
+var tuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Tune engine parameters with SPSA",
+	Long: "Tunes UCI spin options of an engine with simultaneous perturbation stochastic approximation (SPSA).\n" +
+		"The engine plays game pairs against itself, one side with the parameters shifted up and the other with the parameters shifted down.\n" +
+		"The parameter file lists one parameter per line in the format: name value min max step [rate].\n" +
+		"The step is the perturbation and the rate the learning rate at the end of the run (default 0.002).\n" +
+		"After every round a checkpoint is written, which can be continued with --resume.\n",
+
+	Run: func(cmd *cobra.Command, args []string) {
+		conf.Load(tuneFlags.config)
+
+		if !tuneFlags.resume && tuneFlags.params == "" {
+			fmt.Println("Error: a parameter file is required unless resuming")
+			os.Exit(1)
+		}
+
+		err := test.RunTune(test.TuneConfig{
+			Engine:      tuneFlags.engine,
+			Params:      tuneFlags.params,
+			Iterations:  tuneFlags.iterations,
+			Checkpoint:  tuneFlags.checkpoint,
+			Resume:      tuneFlags.resume,
+			TimeControl: tuneFlags.tc,
+			MoveTime:    tuneFlags.movetime,
+			Depth:       tuneFlags.depth,
+			Concurrency: tuneFlags.concurrency,
+			Openings:    tuneFlags.openings,
+			Hash:        tuneFlags.hash,
+			Threads:     tuneFlags.threads,
+		})
+
+		if err != nil {
+			fmt.Println("Error running tuning: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuneCmd)
+	tuneCmd.Flags().StringVarP(&tuneFlags.engine, "engine", "e", "", "Engine binary or name@version of an installed engine")
+	tuneCmd.Flags().StringVarP(&tuneFlags.params, "params", "p", "", "Path to the parameter file")
+	tuneCmd.Flags().IntVarP(&tuneFlags.iterations, "iterations", "n", 1000, "Number of game pairs to play")
+	tuneCmd.Flags().StringVar(&tuneFlags.checkpoint, "checkpoint", "tune.json", "Path to the checkpoint file")
+	tuneCmd.Flags().BoolVar(&tuneFlags.resume, "resume", false, "Continue the run saved in the checkpoint")
+	tuneCmd.Flags().StringVarP(&tuneFlags.tc, "tc", "t", "10+0.1", "Time control in the format [moves/]seconds[+increment]")
+	tuneCmd.Flags().IntVar(&tuneFlags.movetime, "movetime", 0, "Fixed time per move in ms (replaces the time control)")
+	tuneCmd.Flags().IntVar(&tuneFlags.depth, "depth", 0, "Fixed depth per move (replaces the time control)")
+	tuneCmd.Flags().IntVarP(&tuneFlags.concurrency, "concurrency", "j", 0, "Number of games to play at the same time (derived from the hardware by default)")
+	tuneCmd.Flags().StringVarP(&tuneFlags.openings, "openings", "o", "", "Path to a file with one FEN or EPD per line")
+	tuneCmd.Flags().IntVar(&tuneFlags.hash, "hash", 16, "Hash size in MB")
+	tuneCmd.Flags().IntVar(&tuneFlags.threads, "threads", 1, "Number of threads")
+	tuneCmd.Flags().StringVarP(&tuneFlags.config, "config", "c", "", "The path to the configuration file")
+
+	tuneCmd.MarkFlagRequired("engine")
+}
@@ -0,0 +1,137 @@
+package human
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/tui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Init creates the initial commands, which listen to the server and run the clock.
+func (m model) Init() tea.Cmd {
+	return tea.Batch(listen(m.client), tui.Tick(100*time.Millisecond))
+}
+
+// Update updates the view model.
+// It handles the messages of the server and key presses.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tui.TickMsg:
+		return m, tui.Tick(100 * time.Millisecond)
+	case connectionLostMsg:
+		if m.done {
+			return m, nil
+		}
+
+		m.err = errors.New("Connection to game server lost: " + msg.err.Error())
+		return m, tea.Quit
+	case playflow.MoveRequestMsg:
+		if !m.replay(msg.Start, msg.History) {
+			return m, tea.Quit
+		}
+
+		m.request = msg
+		m.pending = true
+		m.received = time.Now()
+		m.color = m.game.Position().Turn()
+		m.status = "Your move"
+	case playflow.UpdateMsg:
+		if !m.replay(msg.Start, msg.History) {
+			return m, tea.Quit
+		}
+	case playflow.GameOverMsg:
+		if !m.replay(msg.Start, msg.History) {
+			return m, tea.Quit
+		}
+
+		m.pending = false
+		m.over = append(m.over, "Game over after "+strconv.Itoa(len(msg.History))+" moves: "+msg.Reason)
+	case playflow.ResultMsg:
+		m.done = true
+		m.over = append(m.over, "Result: "+msg.Describe(m.id), "Press q to quit")
+		return m, nil
+	case playflow.ErrorMsg:
+		if msg.Fatal {
+			m.err = errors.New("Game server error: " + msg.Message)
+			return m, tea.Quit
+		}
+
+		m.status = "Game server error: " + msg.Message
+	default:
+		return m, nil
+	}
+
+	return m, listen(m.client)
+}
+
+// handleKey edits the input and sends the move on enter.
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyEnter:
+		return m.submit()
+	case tea.KeyRunes:
+		if m.done && msg.String() == "q" {
+			return m, tea.Quit
+		}
+
+		m.input += string(msg.Runes)
+	}
+
+	return m, nil
+}
+
+// submit validates the typed move and sends it to the server if it is legal.
+func (m model) submit() (tea.Model, tea.Cmd) {
+	input := strings.TrimSpace(m.input)
+	m.input = ""
+
+	if input == "" {
+		return m, nil
+	}
+
+	if !m.pending {
+		m.status = "Wait for your turn"
+		return m, nil
+	}
+
+	pos := m.game.Position()
+	move, err := parseMove(pos, input)
+
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+
+	san := pos.SAN(move)
+	m.game.Move(move.String())
+	m.pending = false
+	m.status = "Played " + san + ", waiting for the opponent"
+
+	return m, sendMove(m.client, move.String())
+}
+
+// replay sets the game to the position reached by the moves from the start position.
+// If the position is invalid, the error is stored and false is returned.
+func (m *model) replay(start string, history []string) bool {
+	game, err := playflow.Replay(start, history)
+
+	if err != nil {
+		m.err = err
+		return false
+	}
+
+	m.game = game
+	return true
+}
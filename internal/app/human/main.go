@@ -0,0 +1,33 @@
+// Package human provides the view to play against engines on the game server.
+// It is used by the play command to let a human take the place of a player.
+// The view shows the board, the clocks and the history of the game and reads
+// the moves of the player from the keyboard.
+package human
+
+import (
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Play connects to the game server, checks in with the given player id and
+// shows the view until the game is over and the player quits.
+// An error is returned if the connection fails or the server reports a fatal error.
+func Play(id string) error {
+	client, err := com.Connect(conf.GetGameServerConfig().GetURL(), playflow.NewFlow())
+
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+	client.Commands <- playflow.BuildCheckInCmd(id)
+	final, err := tea.NewProgram(initModel(id, client)).Run()
+
+	if err != nil {
+		return err
+	}
+
+	return final.(model).err
+}
@@ -0,0 +1,36 @@
+package human
+
+import (
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
+)
+
+// model is the data model for the view of a human player.
+type model struct {
+	id       string                  // The id of the player
+	client   *com.Client             // The connection to the game server
+	game     *chess.Game             // The game as known to the player or nil before the game started
+	color    chess.Color             // The color of the player, known after the first move request
+	request  playflow.MoveRequestMsg // The last move request, which holds the clocks
+	pending  bool                    // Whether the server waits for a move of the player
+	received time.Time               // The time the last move request was received
+	input    string                  // The move typed by the player
+	status   string                  // Feedback on the last input or server message
+	over     []string                // The end of the game and its result once known
+	done     bool                    // Whether the result was received
+	err      error                   // Any error that ended the game
+}
+
+// initModel initializes the model for the player id with an established connection.
+func initModel(id string, client *com.Client) model {
+	return model{
+		id:     id,
+		client: client,
+		color:  chess.White,
+		status: "Waiting for the game to start",
+		over:   make([]string, 0),
+	}
+}
@@ -0,0 +1,50 @@
+package human
+
+import (
+	"errors"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// connectionLostMsg is sent when the connection to the game server fails.
+type connectionLostMsg struct {
+	err error
+}
+
+// listen creates a command that waits for the next message from the server.
+// The messages of the playflow are returned as they are.
+func listen(client *com.Client) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-client.Messages:
+			return msg
+		case err := <-client.Errors:
+			return connectionLostMsg{err}
+		}
+	}
+}
+
+// sendMove creates a command that sends the move in UCI notation to the server.
+func sendMove(client *com.Client, move string) tea.Cmd {
+	return func() tea.Msg {
+		client.Commands <- playflow.BuildMoveCmd(move)
+		return nil
+	}
+}
+
+// parseMove parses a move in UCI notation or standard algebraic notation and
+// checks whether it is legal in the position.
+func parseMove(pos *chess.Position, input string) (chess.Move, error) {
+	if move, err := pos.ParseMove(input); err == nil {
+		return move, nil
+	}
+
+	if move, err := pos.ParseSAN(input); err == nil {
+		return move, nil
+	}
+
+	return chess.Move{}, errors.New("Illegal move: " + input)
+}
@@ -0,0 +1,88 @@
+package human
+
+import (
+	"strings"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/tui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyLines is the number of full moves shown in the history.
+const historyLines = 12
+
+// View renders the board and history next to each other, followed by the
+// clocks, the status and the input line.
+func (m model) View() string {
+	if m.err != nil {
+		return tui.Error().Render("✘ Error: "+m.err.Error()) + "\n"
+	}
+
+	title := tui.Title().Render("Playing as " + m.id + " (" + m.color.String() + ")")
+
+	if m.game == nil {
+		return lipgloss.NewStyle().Padding(1, 2).Render(title+"\n\n"+m.status) + "\n"
+	}
+
+	board := tui.Box().Render(m.game.Position().Board(true))
+	history := tui.Box().Width(24).Height(lipgloss.Height(board) - 2).Render(m.createHistory())
+	lines := []string{
+		title,
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, board, " ", history),
+		m.createClocks(),
+		"",
+	}
+
+	if len(m.over) > 0 {
+		lines = append(lines, tui.Label().Render(strings.Join(m.over, "\n")))
+	} else {
+		lines = append(lines, m.status, tui.Label().Render("Move: ")+m.input+"█")
+	}
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n")) + "\n"
+}
+
+// createHistory returns the last moves of the game in standard algebraic notation.
+func (m model) createHistory() string {
+	history := m.game.History()
+
+	if len(history) > historyLines {
+		history = history[len(history)-historyLines:]
+	}
+
+	if len(history) == 0 {
+		return "No moves yet"
+	}
+
+	return strings.Join(history, "\n")
+}
+
+// createClocks returns the remaining times of both players as sent with the
+// last move request. The clock of the player runs while a move is pending.
+// Games without a clock show the time for the requested move instead.
+func (m model) createClocks() string {
+	elapsed := 0
+
+	if m.pending {
+		elapsed = int(time.Since(m.received).Milliseconds())
+	}
+
+	if m.request.WTime <= 0 && m.request.BTime <= 0 {
+		if m.request.Time <= 0 {
+			return ""
+		}
+
+		return tui.Label().Render("Time for move: ") + tui.FormatClock(m.request.Time-elapsed)
+	}
+
+	times := [2]int{m.request.WTime, m.request.BTime}
+
+	if m.pending {
+		times[m.color] -= elapsed
+	}
+
+	return tui.Label().Render("White: ") + tui.FormatClock(times[chess.White]) + "   " +
+		tui.Label().Render("Black: ") + tui.FormatClock(times[chess.Black])
+}
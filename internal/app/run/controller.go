@@ -0,0 +1,121 @@
+package run
+
+import (
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/tui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Init creates the initial commands, which wait for updates of the player and run the clock.
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.awaitUpdate, tui.Tick(100*time.Millisecond))
+}
+
+// awaitUpdate waits for the next message of the player or new lines in the log.
+func (m model) awaitUpdate() tea.Msg {
+	select {
+	case msg := <-m.updates:
+		return msg
+	case <-m.output.changed:
+		return refreshMsg{}
+	}
+}
+
+// Update updates the view model.
+// It handles the messages of the player and the engine, key presses and resizes.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+
+		var cmd tea.Cmd
+		m.log, cmd = m.log.Update(msg)
+		return m, cmd
+	case tea.WindowSizeMsg:
+		m.log.Width = msg.Width - 6
+		m.log.Height = msg.Height - 18
+
+		if m.log.Height < 5 {
+			m.log.Height = 5
+		}
+
+		m.setLog()
+		return m, nil
+	case tui.TickMsg:
+		if m.client != nil {
+			m.ping = m.client.Ping()
+		}
+
+		return m, tui.Tick(100 * time.Millisecond)
+	case refreshMsg:
+		m.setLog()
+	case statusMsg:
+		m.status = string(msg)
+	case connectionMsg:
+		m.client = msg.client
+		m.connection = msg.state
+	case requestMsg:
+		m.replay(msg.request.Start, msg.request.History)
+		m.request = msg.request
+		m.searching = true
+		m.playing = true
+		m.started = time.Now()
+		m.info = nil
+
+		if m.game != nil {
+			m.root = m.game.Position()
+		}
+
+		if len(msg.request.History) < 2 {
+			m.times = m.times[:0]
+		}
+	case moveMsg:
+		m.searching = false
+		m.times = append(m.times, msg.elapsed)
+
+		if m.game != nil {
+			m.game.Move(msg.move)
+		}
+	case gameMsg:
+		m.replay(msg.start, msg.history)
+		m.playing = !msg.over
+	case doneMsg:
+		m.done = true
+		m.err = msg.err
+		m.client = nil
+		m.connection = closed
+		m.searching = false
+		return m, nil
+	}
+
+	return m, m.awaitUpdate
+}
+
+// setLog updates the content of the log pane and the last info of the engine
+// while it is searching. The log follows new lines unless it was scrolled up.
+func (m *model) setLog() {
+	content, info := m.output.snapshot()
+	follow := m.log.AtBottom()
+	m.log.SetContent(content)
+
+	if m.searching {
+		m.info = info
+	}
+
+	if follow {
+		m.log.GotoBottom()
+	}
+}
+
+// replay sets the game to the position reached by the moves from the start position.
+// The game is left unchanged if the moves are not legal.
+func (m *model) replay(start string, history []string) {
+	if game, err := playflow.Replay(start, history); err == nil {
+		m.game = game
+	}
+}
@@ -0,0 +1,169 @@
+package run
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type connectionState string
+
+const (
+	connecting   connectionState = "connecting"
+	connected    connectionState = "connected"
+	reconnecting connectionState = "reconnecting"
+	closed       connectionState = "closed"
+)
+
+// maxLogLines is the number of lines kept in the raw log.
+const maxLogLines = 1000
+
+// refreshMsg is sent when new lines were added to the raw log.
+type refreshMsg struct{}
+
+// statusMsg is a line printed by the player, e.g. the result of a game.
+type statusMsg string
+
+// connectionMsg is sent when the connection to the game server changes.
+// The client is nil unless the player is connected.
+type connectionMsg struct {
+	client *com.Client
+	state  connectionState
+}
+
+// requestMsg is sent when the server requests a move and the engine starts searching.
+type requestMsg struct {
+	request playflow.MoveRequestMsg
+}
+
+// moveMsg is sent when the engine found a move.
+type moveMsg struct {
+	move    string
+	elapsed time.Duration
+}
+
+// gameMsg is sent when the server sent the moves of the game, e.g. after a reconnect.
+// Over is set if the server reported the end of the game.
+type gameMsg struct {
+	start   string
+	history []string
+	over    bool
+}
+
+// doneMsg is sent when the player stopped playing.
+type doneMsg struct {
+	err error
+}
+
+// model is the data model for the view of an engine playing on the game server.
+type model struct {
+	id         string                  // The id of the player
+	updates    chan tea.Msg            // The messages of the player and the engine
+	client     *com.Client             // The connection to the game server or nil if disconnected
+	connection connectionState         // The state of the connection
+	ping       int64                   // The last measured ping in ms
+	game       *chess.Game             // The game as known to the player or nil before the game started
+	request    playflow.MoveRequestMsg // The last move request, which holds the clocks
+	searching  bool                    // Whether the engine is searching
+	started    time.Time               // The time the engine started the current search
+	root       *chess.Position         // The position of the current or last search
+	info       *uci.MoveInfo           // The last info of the engine with a score, if any
+	times      []time.Duration         // The time the engine used for each move of the game
+	playing    bool                    // Whether a game is in progress
+	output     *outputLog              // The raw log, which is written by the player and the engine
+	log        viewport.Model          // The scrollable pane of the raw log
+	status     string                  // The last line printed by the player
+	done       bool                    // Whether the player stopped playing
+	err        error                   // The error which stopped the player, if any
+}
+
+// initModel initializes the model for the player id, which reports to the update channel.
+func initModel(id string, updates chan tea.Msg, output *outputLog) model {
+	return model{
+		id:         id,
+		updates:    updates,
+		connection: connecting,
+		times:      make([]time.Duration, 0),
+		output:     output,
+		log:        viewport.New(80, 10),
+	}
+}
+
+// outputLog keeps the last lines printed by the player and sent to or received
+// from the engine in a ring buffer, together with the last info of the engine
+// which carries a principal variation.
+// Writers never block: the view is signalled once for any number of new lines.
+type outputLog struct {
+	mutex   sync.Mutex
+	lines   []string      // The ring buffer of the lines
+	next    int           // The index of the next line in the ring buffer
+	info    *uci.MoveInfo // The last info of the current search, if any
+	changed chan struct{} // Signals new lines to the view
+}
+
+// newOutputLog creates an empty log.
+func newOutputLog() *outputLog {
+	return &outputLog{
+		lines:   make([]string, 0, maxLogLines),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// add appends a line to the log and replaces the oldest line if the log is full.
+func (l *outputLog) add(line string) {
+	l.mutex.Lock()
+
+	if len(l.lines) < maxLogLines {
+		l.lines = append(l.lines, line)
+	} else {
+		l.lines[l.next] = line
+	}
+
+	l.next = (l.next + 1) % maxLogLines
+	l.mutex.Unlock()
+
+	select {
+	case l.changed <- struct{}{}:
+	default:
+	}
+}
+
+// receive adds a line received from the engine and keeps it as the last info
+// if it carries a principal variation.
+func (l *outputLog) receive(line string) {
+	if info := uci.ParseInfo(line); info != nil && len(info.Pv) > 0 {
+		l.mutex.Lock()
+		l.info = info
+		l.mutex.Unlock()
+	}
+
+	l.add(line)
+}
+
+// clearInfo drops the last info before a new search starts.
+func (l *outputLog) clearInfo() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.info = nil
+}
+
+// snapshot returns the lines of the log from the oldest to the newest line
+// and the last info of the current search.
+func (l *outputLog) snapshot() (string, *uci.MoveInfo) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.lines) < maxLogLines {
+		return strings.Join(l.lines, "\n"), l.info
+	}
+
+	return strings.Join(l.lines[l.next:], "\n") + "\n" + strings.Join(l.lines[:l.next], "\n"), l.info
+}
@@ -0,0 +1,191 @@
+package run
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// console writes the output of several players to stdout.
+// Lines are never interleaved and prefixed with the id of their player.
+type console struct {
+	mutex sync.Mutex
+}
+
+// printer returns a function which prints a line with the given prefix.
+func (c *console) printer(prefix string) func(string) {
+	return func(line string) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		fmt.Println(prefix + line)
+	}
+}
+
+// PlayAll plays the games of all player ids at the same time.
+// Each player has its own connection to the game server and its own engine
+// process, launched from the given binary. The output of the players and their
+// engines is printed to stdout. With more than one player each line is prefixed
+// with the player id.
+// An error is returned if any player failed.
+func PlayAll(exe string, ids []string, stay bool) error {
+	out := &console{}
+	errs := make(chan error, len(ids))
+	wg := sync.WaitGroup{}
+
+	for _, id := range ids {
+		prefix := ""
+
+		if len(ids) > 1 {
+			prefix = "[" + id + "] "
+		}
+
+		output := out.printer(prefix)
+		launch := func() (*uci.UCI, error) {
+			return uci.NewFromExe(exe, out.printer(prefix+"> "), output)
+		}
+		ifc, err := launch()
+
+		if err != nil {
+			output("Error starting engine: " + err.Error())
+			errs <- err
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(p *player) {
+			defer wg.Done()
+			defer p.close()
+
+			if err := play(p); err != nil {
+				if len(ids) > 1 {
+					p.out("Error playing game: " + err.Error())
+				}
+
+				errs <- err
+			}
+		}(&player{ifc: ifc, launch: launch, id: id, stay: stay, out: output})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if failed := len(errs); failed > 0 {
+		if len(ids) == 1 {
+			return <-errs
+		}
+
+		return errors.New(strconv.Itoa(failed) + " of " + strconv.Itoa(len(ids)) + " players failed")
+	}
+
+	return nil
+}
+
+// PlayView plays the games of a single player id like PlayAll, but shows the
+// board, the search of the engine and the connection in a terminal user interface.
+// The output of the player and the engine is shown in a scrollable log.
+// If the view is closed while a game is in progress, the player leaves the game
+// and an error is returned.
+func PlayView(exe string, id string, stay bool) error {
+	updates := make(chan tea.Msg, 256)
+	stop := make(chan struct{})
+	output := newOutputLog()
+	send := func(msg tea.Msg) {
+		select {
+		case updates <- msg:
+		case <-stop:
+		}
+	}
+	launch := func() (*uci.UCI, error) {
+		return uci.NewFromExe(
+			exe,
+			func(line string) { output.add("> " + line) },
+			output.receive,
+		)
+	}
+	ifc, err := launch()
+
+	if err != nil {
+		return err
+	}
+
+	p := &player{
+		ifc:    ifc,
+		launch: launch,
+		id:     id,
+		stay:   stay,
+		out: func(line string) {
+			output.add(line)
+			send(statusMsg(line))
+		},
+		notify: func(msg any) {
+			if _, ok := msg.(requestMsg); ok {
+				output.clearInfo()
+			}
+
+			send(msg)
+		},
+		stop: stop,
+	}
+	done := make(chan error, 1)
+
+	go func() {
+		err := play(p)
+		done <- err
+		send(doneMsg{err})
+	}()
+
+	final, err := tea.NewProgram(initModel(id, updates, output), tea.WithAltScreen()).Run()
+	close(stop)
+	p.close()
+	result := <-done
+
+	if err != nil {
+		return err
+	}
+
+	if m := final.(model); m.playing && !m.done {
+		return errors.New("Left the game before it was finished")
+	}
+
+	if result == errStopped {
+		return nil
+	}
+
+	return result
+}
+
+// LoadPlayers reads player ids from a file with one id per line.
+// Empty lines and lines starting with '#' are ignored.
+func LoadPlayers(path string) ([]string, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	ids := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ids = append(ids, line)
+	}
+
+	return ids, scanner.Err()
+}
@@ -1,59 +1,317 @@
 package run
 
 import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/playflow"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
 )
 
-// Play starts a game against the game server.
+// errConnectionLost is returned by serve if the connection dropped before the player finished.
+var errConnectionLost = errors.New("Connection to game server lost")
+
+// resultTimeout is the time the player waits for the result after the game is over.
+// The server sends no result for an aborted game.
+const resultTimeout = 10 * time.Second
+
+// errStopped is returned by the player if it was stopped before it finished.
+var errStopped = errors.New("Player was stopped")
+
+// player plays the games of a player id with an engine.
+// The engine keeps running when the connection to the server is restored.
+// If the engine crashes, a new engine is launched in its place.
+type player struct {
+	mutex    sync.Mutex // Guards ifc and closed, which are shared with the view.
+	ifc      *uci.UCI
+	closed   bool
+	launch   func() (*uci.UCI, error) // Starts a new instance of the engine.
+	restarts int                      // The number of restarts of the engine in the current game.
+	id       string
+	stay     bool
+	over     bool
+	out      func(string)
+	notify   func(any)       // Receives the state of the game for the view, if any.
+	stop     <-chan struct{} // Stops the player when closed, if set.
+}
+
+// play starts the games of the player against the game server.
 // It will connect to the server and send a check-in message.
 // After that it will wait for a move request and send the move to the server.
-// This process will repeat until the server closes the connection.
-func Play(ifc *uci.UCI, id string) error {
-	flow := playflow.NewFlow()
-	client, err := com.Connect(conf.GetGameServerConfig().GetURL(), flow)
+// This process will repeat until the game is over. The result is printed and,
+// if stay is set, the player checks in again for the next game with the same id.
+// If the connection drops, the player reconnects with an increasing delay, checks
+// in again and continues the game from the state sent by the server.
+// An error is returned if the server reports a fatal error or the connection
+// could not be restored.
+func play(p *player) error {
+	client, err := com.Connect(conf.GetGameServerConfig().GetURL(), playflow.NewFlow())
 
 	if err != nil {
 		return err
 	}
 
-	checkIn := playflow.BuildCheckInCmd(id)
+	client.Commands <- playflow.BuildCheckInCmd(p.id)
+	p.emit(connectionMsg{client: client, state: connected})
+	p.engine().Setup()
+	p.engine().Start()
+
+	for {
+		err := p.serve(client)
+		client.Close()
+
+		if err != errConnectionLost {
+			return err
+		}
+
+		p.out("Connection to game server lost, reconnecting...")
+		p.emit(connectionMsg{state: reconnecting})
+
+		if client, err = p.reconnect(); err != nil {
+			return err
+		}
+	}
+}
+
+// serve handles the messages of the server until the player is done or the connection dropped.
+// If no result arrives in time after the game is over, the game is considered aborted.
+func (p *player) serve(client *com.Client) error {
 	closeChan := make(chan bool)
+	var result <-chan time.Time
 
-	client.Commands <- checkIn
-	ifc.Setup()
-	ifc.Start()
+	if p.over {
+		result = time.After(resultTimeout)
+	}
 
 	go listenForErrors(client, closeChan)
 
+	send := func(cmd com.Command) bool {
+		select {
+		case client.Commands <- cmd:
+			return true
+		case <-closeChan:
+			return false
+		}
+	}
+
+	// next prepares the engine for the next game and checks in again.
+	next := func() bool {
+		p.over = false
+		p.restarts = 0
+		result = nil
+		p.engine().Start()
+
+		return send(playflow.BuildCheckInCmd(p.id))
+	}
+
 	for {
 		select {
 		case <-closeChan:
-			return nil
+			return errConnectionLost
+		case <-p.stop:
+			return errStopped
+		case <-result:
+			p.out("No result received, the game was aborted")
+
+			if !p.stay {
+				return errors.New("Game was aborted")
+			}
+
+			if !next() {
+				return errConnectionLost
+			}
 		case m := <-client.Messages:
 			switch msg := m.(type) {
 			case playflow.MoveRequestMsg:
-				client.Commands <- playflow.BuildMoveCmd(fetchMove(ifc, msg.Time, msg.Start, msg.History))
+				p.emit(requestMsg{msg})
+				start := time.Now()
+				move, err := p.fetchMove(msg)
+
+				if err != nil {
+					return err
+				}
+
+				p.emit(moveMsg{move: move, elapsed: time.Since(start)})
+
+				if !send(playflow.BuildMoveCmd(move)) {
+					return errConnectionLost
+				}
+			case playflow.UpdateMsg:
+				p.engine().SetPosition(msg.Start, msg.History...)
+				p.emit(gameMsg{start: msg.Start, history: msg.History})
+				p.out("Resumed game after " + strconv.Itoa(len(msg.History)) + " moves")
+			case playflow.GameOverMsg:
+				p.over = true
+				result = time.After(resultTimeout)
+				p.emit(gameMsg{start: msg.Start, history: msg.History, over: true})
+				p.out("Game over after " + strconv.Itoa(len(msg.History)) + " moves: " + msg.Reason)
+			case playflow.ResultMsg:
+				p.out("Result: " + msg.Describe(p.id))
+
+				if !p.stay {
+					return nil
+				}
+
+				if !next() {
+					return errConnectionLost
+				}
+			case playflow.ErrorMsg:
+				if msg.Fatal {
+					return errors.New("Game server error: " + msg.Message)
+				}
+
+				p.out("Game server error: " + msg.Message)
 			default:
 				continue
 			}
 		}
+	}
+}
+
+// reconnect connects to the server again, checks in with the id of the player
+// and requests the current state of the game.
+// The delay between the attempts doubles after each failed attempt.
+func (p *player) reconnect() (*com.Client, error) {
+	cfg := conf.GetReconnectConfig()
+	delay := cfg.InitialDelay
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		select {
+		case <-time.After(delay):
+		case <-p.stop:
+			return nil, errStopped
+		}
+
+		client, err := com.Connect(conf.GetGameServerConfig().GetURL(), playflow.NewFlow())
+
+		if err == nil {
+			client.Commands <- playflow.BuildCheckInCmd(p.id)
+			client.Commands <- playflow.BuildUpdateReqCmd("", p.id)
+			p.out("Reconnected to game server")
+			p.emit(connectionMsg{client: client, state: connected})
+			return client, nil
+		}
 
+		p.out("Reconnect attempt " + strconv.Itoa(attempt) + " failed: " + err.Error())
+
+		if delay *= 2; delay > cfg.MaxDelay {
+			delay = cfg.MaxDelay
+		}
+	}
+
+	if p.over {
+		return nil, errors.New("Connection to game server lost before the result was received")
+	}
+
+	return nil, errors.New("Connection to game server lost during the game")
+}
+
+// emit passes the message to the view of the player, if any.
+func (p *player) emit(msg any) {
+	if p.notify != nil {
+		p.notify(msg)
 	}
 }
 
+// listenForErrors closes the connection and the signal channel as soon as the connection fails.
 func listenForErrors(client *com.Client, signal chan bool) {
 	for range client.Errors {
 		client.Close()
-		signal <- true
+		close(signal)
 		break
 	}
 }
 
-func fetchMove(ifc *uci.UCI, time int, start string, moves []string) string {
-	ifc.SetPosition(start, moves...)
-	move := ifc.GetMove(time)
-	return move.Move
+// fetchMove lets the engine search the requested position. With a clock the
+// engine manages its own time based on the remaining times and increments.
+// If the engine crashes or hangs, it is restarted and searches the position
+// again with the time that is left, until the restart limit is reached.
+func (p *player) fetchMove(msg playflow.MoveRequestMsg) (string, error) {
+	received := time.Now()
+
+	for {
+		limits := msg.LimitsAfter(conf.GetLatencyOverhead(), time.Since(received))
+		ifc := p.engine()
+		ifc.SetPosition(msg.Start, msg.History...)
+
+		if info := ifc.SearchTimeout(limits, searchTimeout(msg, limits)); info != nil {
+			return info.Move, nil
+		}
+
+		if p.restarts >= conf.GetEngineRestarts() {
+			return "", errors.New("Engine stopped responding after " + strconv.Itoa(p.restarts) + " restarts")
+		}
+
+		p.restarts++
+		p.out("Engine stopped responding, restarting (" + strconv.Itoa(p.restarts) + "/" + strconv.Itoa(conf.GetEngineRestarts()) + ")")
+
+		if err := p.restart(); err != nil {
+			return "", err
+		}
+	}
+}
+
+// engine returns the current instance of the engine.
+func (p *player) engine() *uci.UCI {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.ifc
+}
+
+// close kills the engine of the player. It must not be restarted afterwards.
+func (p *player) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closed = true
+	p.ifc.Close()
+}
+
+// restart kills the engine and launches a new instance, which is set up for a new game.
+func (p *player) restart() error {
+	p.mutex.Lock()
+
+	if p.closed {
+		p.mutex.Unlock()
+		return errStopped
+	}
+
+	p.ifc.Close()
+	ifc, err := p.launch()
+
+	if err == nil {
+		p.ifc = ifc
+	}
+
+	p.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	ifc.Setup()
+	ifc.Start()
+
+	return nil
+}
+
+// searchTimeout returns the time after which a search with the given limits is
+// considered hung. This is the remaining time of the side to move or the move
+// time, plus the latency overhead, so an engine is never stopped while it may
+// still use its time.
+func searchTimeout(msg playflow.MoveRequestMsg, limits uci.Limits) time.Duration {
+	ms := limits.MoveTime
+
+	if ms == 0 {
+		if ms = limits.BTime; msg.WhiteToMove() {
+			ms = limits.WTime
+		}
+	}
+
+	return time.Duration(ms)*time.Millisecond + conf.GetLatencyOverhead()
 }
@@ -0,0 +1,180 @@
+package run
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/tui"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	historyLines = 10 // The number of full moves shown in the history.
+	pvLength     = 8  // The number of moves of the principal variation shown.
+)
+
+// View renders the board, the search of the engine and the history next to
+// each other, followed by the status and the raw log.
+func (m model) View() string {
+	header := tui.Title().Render("Playing as "+m.id) + "   " +
+		tui.Label().Render("Connection: ") + string(m.connection) + "   " +
+		tui.Label().Render("Ping: ") + strconv.FormatInt(m.ping, 10) + "ms"
+
+	pos, _ := chess.ParseFEN(chess.StartFEN)
+
+	if m.game != nil {
+		pos = m.game.Position()
+	}
+
+	board := tui.Box().Render(pos.Board(true))
+
+	height := lipgloss.Height(board) - 2
+	search := tui.Box().Width(42).Height(height).Render(m.createSearch())
+	history := tui.Box().Width(24).Height(height).Render(m.createHistory())
+	status := m.status
+
+	if m.err != nil {
+		status = tui.Error().Render("✘ Error: " + m.err.Error())
+	}
+
+	if m.done {
+		status += "\n" + tui.Label().Render("Finished, press q to quit")
+	}
+
+	lines := []string{
+		header,
+		lipgloss.JoinHorizontal(lipgloss.Top, board, " ", search, " ", history),
+		status,
+		tui.Box().Width(m.log.Width + 2).Render(m.log.View()),
+		"↑/↓ pgup/pgdown scroll the log, q quits",
+	}
+
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n")) + "\n"
+}
+
+// createSearch returns the clocks, the time used per move and the evaluation
+// and principal variation of the current or last search of the engine.
+func (m model) createSearch() string {
+	elapsed := time.Duration(0)
+
+	if m.searching {
+		elapsed = time.Since(m.started)
+	}
+
+	rows := []string{
+		m.createClocks(elapsed),
+		tui.Label().Render("This move: ") + formatDuration(elapsed),
+		tui.Label().Render("Per move:  ") + m.createTimes(),
+		"",
+	}
+
+	if m.info == nil || m.root == nil {
+		return strings.Join(append(rows, tui.Label().Render("Eval: ")+"-"), "\n")
+	}
+
+	rows = append(rows,
+		tui.Label().Render("Eval: ")+formatScore(m.info.Score, m.root.Turn())+
+			"   "+tui.Label().Render("Depth: ")+strconv.Itoa(m.info.Depth)+
+			"   "+tui.Label().Render("NPS: ")+tui.FormatCount(float64(m.info.Nps)),
+		tui.Label().Render("PV: ")+formatPv(m.root, m.info.Pv),
+	)
+
+	return strings.Join(rows, "\n")
+}
+
+// createClocks returns the remaining times of both players as sent with the
+// last move request. The clock of the engine runs while it is searching.
+// Games without a clock show the time for the requested move instead.
+func (m model) createClocks(elapsed time.Duration) string {
+	ms := int(elapsed.Milliseconds())
+
+	if m.request.WTime <= 0 && m.request.BTime <= 0 {
+		return tui.Label().Render("Move time: ") + tui.FormatClock(m.request.Time-ms)
+	}
+
+	times := [2]int{m.request.WTime, m.request.BTime}
+
+	if m.searching && m.game != nil {
+		times[m.game.Position().Turn()] -= ms
+	}
+
+	return tui.Label().Render("White: ") + tui.FormatClock(times[chess.White]) + "   " +
+		tui.Label().Render("Black: ") + tui.FormatClock(times[chess.Black])
+}
+
+// createTimes returns the time used for the last move and the mean time per move of the game.
+func (m model) createTimes() string {
+	if len(m.times) == 0 {
+		return "-"
+	}
+
+	sum := time.Duration(0)
+
+	for _, t := range m.times {
+		sum += t
+	}
+
+	return formatDuration(m.times[len(m.times)-1]) + " last, " + formatDuration(sum/time.Duration(len(m.times))) + " mean"
+}
+
+// createHistory returns the last moves of the game in standard algebraic notation.
+func (m model) createHistory() string {
+	if m.game == nil || len(m.game.Moves()) == 0 {
+		return "No moves yet"
+	}
+
+	history := m.game.History()
+
+	if len(history) > historyLines {
+		history = history[len(history)-historyLines:]
+	}
+
+	return strings.Join(history, "\n")
+}
+
+// formatScore returns the score of the engine from the perspective of white.
+func formatScore(score uci.Score, turn chess.Color) string {
+	value := score.Value
+
+	if turn == chess.Black {
+		value = -value
+	}
+
+	if score.Type == uci.Mate {
+		return fmt.Sprintf("#%d", value)
+	}
+
+	return fmt.Sprintf("%+.2f", float64(value)/100)
+}
+
+// formatPv returns the first moves of the principal variation in standard algebraic notation.
+// The variation is cut at the first move which is not legal.
+func formatPv(pos *chess.Position, pv []string) string {
+	moves := make([]string, 0, pvLength)
+
+	for _, uci := range pv {
+		if len(moves) == pvLength {
+			break
+		}
+
+		move, err := pos.ParseMove(uci)
+
+		if err != nil {
+			break
+		}
+
+		moves = append(moves, pos.SAN(move))
+		pos = pos.Play(move)
+	}
+
+	return strings.Join(moves, " ")
+}
+
+// formatDuration returns the duration in seconds with two decimals, e.g. "1.25s".
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+)
+
+// closeTimeout is the time the worker waits for its last commands to be written before it exits.
+const closeTimeout = 2 * time.Second
+
+// connection is the connection of the worker to the test server, which is
+// replaced by a new client whenever the connection was restored.
+// Commands sent while the connection is down are dropped. Reports are kept in
+// the outbox and replayed after reconnecting.
+type connection struct {
+	mutex  sync.Mutex
+	client *com.Client
+	lost   chan struct{} // Closed when the current client failed.
+	failed chan struct{} // Closed when the connection could not be restored.
+	err    error         // The reason why the connection could not be restored.
+	closed bool          // Whether the connection was closed by the worker.
+}
+
+// newConnection creates a connection for the connected client.
+// If the client could not connect, the connection is failed with the given error.
+func newConnection(client *com.Client, err error) *connection {
+	c := &connection{
+		client: client,
+		lost:   make(chan struct{}),
+		failed: make(chan struct{}),
+	}
+
+	if err != nil || client == nil {
+		c.abandon(err)
+	}
+
+	return c
+}
+
+// current returns the current client and the channel which is closed when it fails.
+func (c *connection) current() (*com.Client, chan struct{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.client, c.lost
+}
+
+// send sends the command with the current client.
+// It returns false if the command was dropped because the connection is down.
+func (c *connection) send(cmd com.Command) bool {
+	client, lost := c.current()
+
+	if client == nil {
+		return false
+	}
+
+	select {
+	case client.Commands <- cmd:
+		return true
+	case <-lost:
+		return false
+	}
+}
+
+// drop closes the failed client and marks the connection as lost.
+// It returns false if the connection was closed by the worker and must not be restored.
+func (c *connection) drop() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.client.Close()
+	close(c.lost)
+
+	return !c.closed
+}
+
+// replace sets the client of a restored connection.
+// It returns false if the connection was closed by the worker in the meantime.
+func (c *connection) replace(client *com.Client) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	c.client = client
+	c.lost = make(chan struct{})
+
+	return true
+}
+
+// abandon marks the connection as failed for good.
+func (c *connection) abandon(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err == nil {
+		err = errors.New("Connection to test server lost")
+	}
+
+	c.err = err
+	close(c.failed)
+}
+
+// reason returns the error why the connection could not be restored.
+func (c *connection) reason() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+// close writes the commands sent before and closes the current client.
+// The connection is not restored afterwards.
+func (c *connection) close() {
+	c.mutex.Lock()
+	c.closed = true
+	client := c.client
+	c.mutex.Unlock()
+
+	if client != nil {
+		client.Shutdown(closeTimeout)
+	}
+}
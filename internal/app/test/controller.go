@@ -2,11 +2,13 @@ package test
 
 import (
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/stats"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(m.service.register, m.uptime.Init())
+	return tea.Batch(m.service.register, m.uptime.Init(), m.awaitUpdate)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -21,32 +23,138 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
-			m.data.state = quit
-			return m, tea.Quit
+			return m, m.stop()
+		case "left", "up":
+			m.data.selectGame(-1)
+			return m, nil
+		case "right", "down":
+			m.data.selectGame(1)
+			return m, nil
 		default:
 			return m, nil
 		}
 	case registerMsg:
 		m.data.state = wait
-		return m, m.service.awaitGameStart
+		return m, tea.Batch(m.service.replay, m.service.awaitGameStart)
 	case startMsg:
-		m.data.state = play
-		m.data.session = msg.session
-		m.data.engines = msg.engines
-		m.data.search = msg.search
-		m.data.options = msg.options
-		m.data.concurrency = m.service.getConcurrency(msg.options)
-		return m, func() tea.Msg {
-			return m.service.dispatchGames(msg.batch, m.data)
+		return m, m.startGames(msg)
+	case suiteErrorMsg:
+		m.service.conn.send(msg.cmd)
+		return m, m.service.awaitGameStart
+	case pairMsg:
+		m.data.addResults(msg.results)
+		m.data.addSamples(msg.samples)
+		return m, m.awaitUpdate
+	case liveBatch:
+		if m.data.state == play || m.data.state == drain {
+			for _, live := range msg {
+				m.data.updateLive(live)
+			}
 		}
+
+		return m, m.awaitUpdate
 	case gameMsg:
-		m.data.state = wait
-		m.data.played += msg.gameCount
-		m.service.client.Commands <- testflow.BuildReportCmd(m.data.session, msg.moves, msg.logs)
-		return m, m.service.awaitGameStart
+		draining := m.data.state == drain
+		cmd := m.reportGames(msg)
+
+		if draining {
+			m.service.conn.send(testflow.BuildDeregisterCmd())
+			m.data.state = quit
+			return m, m.shutdown
+		}
+
+		return m, cmd
 	}
 
 	var cmd tea.Cmd
 	m.uptime, cmd = m.uptime.Update(msg)
 	return m, cmd
 }
+
+// awaitUpdate waits for the next update of the games in progress,
+// i.e. the played moves or the results of a finished game pair.
+func (m model) awaitUpdate() tea.Msg {
+	select {
+	case msg := <-m.updates:
+		return msg
+	case <-m.live.ready:
+		return m.live.take()
+	}
+}
+
+// stop handles a request to quit the worker.
+// The first request while games are played stops scheduling new games and lets
+// the running ones finish, so they are reported before the worker deregisters.
+// A second request, or a request without running games, quits immediately.
+func (m model) stop() tea.Cmd {
+	switch m.data.state {
+	case play:
+		m.data.state = drain
+		close(m.service.drain)
+		return nil
+	case drain:
+		m.data.state = quit
+		return tea.Quit
+	default:
+		m.service.conn.send(testflow.BuildDeregisterCmd())
+		m.data.state = quit
+		return m.shutdown
+	}
+}
+
+// shutdown closes the connection once the last commands were written and quits.
+func (m model) shutdown() tea.Msg {
+	m.service.conn.close()
+	return tea.Quit()
+}
+
+// startGames applies the configuration of a started session and returns
+// the command which plays the requested batch of games.
+func (m model) startGames(msg startMsg) tea.Cmd {
+	if msg.session != m.data.session {
+		m.data.games = stats.Trinomial{}
+		m.data.pairs = stats.Pentanomial{}
+		m.data.totals = [2]testflow.PerfTotals{}
+		m.data.perf = [2]testflow.PerfStats{}
+	}
+
+	m.data.state = play
+	m.data.live = nil
+	m.data.session = msg.session
+	m.data.engines = msg.engines
+	m.data.search = msg.search
+	m.data.options = msg.options
+	m.data.trace = msg.trace
+	m.data.concurrency = m.service.getConcurrency(msg.options)
+
+	return func() tea.Msg {
+		return m.service.dispatchGames(msg.batch, m.data)
+	}
+}
+
+// reportGames sends the results of a finished batch to the server and returns
+// the command which waits for the next session to start.
+func (m model) reportGames(msg gameMsg) tea.Cmd {
+	m.data.state = wait
+	m.data.live = nil
+	m.data.played += msg.gameCount
+	m.data.aborted = msg.aborted
+	id := m.service.reportID()
+	cmds, err := testflow.BuildReportCmds(id, m.data.session, msg.moves, msg.logs, msg.results, summarize(msg.samples), msg.aborted, m.service.verbosity(), conf.GetReportConfig().MaxSize)
+
+	if err != nil {
+		return func() tea.Msg {
+			return err
+		}
+	}
+
+	for _, cmd := range cmds {
+		if err := m.service.deliver(cmd.Id, cmd); err != nil {
+			return func() tea.Msg {
+				return err
+			}
+		}
+	}
+
+	return m.service.awaitGameStart
+}
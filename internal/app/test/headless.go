@@ -0,0 +1,175 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/stats"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logger writes structured log lines in the format
+// "time=<timestamp> event=<event> key=value ..." to stdout.
+type logger struct{}
+
+// RunHeadless runs the test worker without a terminal user interface.
+// It uses the same register, await and dispatch loop as the view model,
+// but reports its progress as structured log lines on stdout.
+// The first SIGINT or SIGTERM drains the running games, reports them and
+// deregisters the worker. A second signal exits immediately.
+func RunHeadless() error {
+	m := initModel()
+	log := logger{}
+	msgs := make(chan tea.Msg)
+	signals := make(chan os.Signal, 1)
+
+	if m.data.err != nil {
+		log.event("error", "message", m.data.err.Error())
+		return m.data.err
+	}
+
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	defer m.service.conn.close()
+
+	exec := func(cmd tea.Cmd) {
+		go func() {
+			msgs <- cmd()
+		}()
+	}
+
+	log.event("connected", "cores", m.data.cores, "memory", m.data.memory)
+	exec(m.service.register)
+	exec(m.awaitUpdate)
+
+	for {
+		select {
+		case sig := <-signals:
+			if m.data.state == play {
+				m.stop()
+				log.event("draining", "signal", sig.String(), "remaining", m.service.remaining())
+				continue
+			}
+
+			m.stop()
+			log.event("shutdown", "signal", sig.String(), "played", m.data.played)
+			return nil
+		case msg := <-msgs:
+			switch msg := msg.(type) {
+			case error:
+				log.event("error", "message", msg.Error())
+				return msg
+			case registerMsg:
+				m.data.state = wait
+				log.event("registered", "id", msg.id)
+				exec(m.service.replay)
+				exec(m.service.awaitGameStart)
+			case suiteErrorMsg:
+				m.service.conn.send(msg.cmd)
+				log.event(
+					"suite-error",
+					"session", msg.cmd.Session,
+					"engine", msg.cmd.Engine,
+					"message", msg.cmd.Message,
+				)
+				exec(m.service.awaitGameStart)
+			case startMsg:
+				cmd := m.startGames(msg)
+				log.event(
+					"start",
+					"session", msg.session,
+					"engines", m.describeEngines(),
+					"batch", msg.batch,
+					"concurrency", m.data.concurrency,
+				)
+				exec(cmd)
+			case pairMsg:
+				m.data.addResults(msg.results)
+				m.data.addSamples(msg.samples)
+				exec(m.awaitUpdate)
+			case liveBatch:
+				exec(m.awaitUpdate)
+			case gameMsg:
+				draining := m.data.state == drain
+				cmd := m.reportGames(msg)
+
+				if msg.aborted != "" {
+					log.event("abort", "session", m.data.session, "reason", msg.aborted)
+				}
+
+				sprt := m.data.sprt()
+				llr := sprt.LLR(m.data.pairs)
+				perf := summarize(msg.samples)
+				log.event(
+					"report",
+					"session", m.data.session,
+					"games", msg.gameCount,
+					"played", m.data.played,
+					"wdl", fmt.Sprintf("%d-%d-%d", m.data.games.Wins, m.data.games.Draws, m.data.games.Losses),
+					"elo", fmt.Sprintf("%.1f", m.data.elo().Elo),
+					"los", fmt.Sprintf("%.3f", m.data.games.LOS()),
+					"llr", fmt.Sprintf("%.2f", llr),
+					"sprt", describeDecision(sprt.Decide(llr)),
+					"depth", fmt.Sprintf("%.1f/%.1f", perf[0].MeanDepth, perf[1].MeanDepth),
+					"nps", fmt.Sprintf("%.0f/%.0f", perf[0].MeanNps, perf[1].MeanNps),
+					"overshoots", fmt.Sprintf("%d/%d", perf[0].Overshoots, perf[1].Overshoots),
+				)
+
+				if draining {
+					m.service.conn.send(testflow.BuildDeregisterCmd())
+					log.event("shutdown", "played", m.data.played)
+					return nil
+				}
+
+				exec(cmd)
+			}
+		}
+	}
+}
+
+// describeEngines returns the names and versions of the engines of the current session.
+func (m model) describeEngines() string {
+	names := make([]string, 0, len(m.data.engines))
+
+	for _, e := range m.data.engines {
+		names = append(names, e.Engine+"@"+e.Version.String(mgmt.DotVersionStyle))
+	}
+
+	return strings.Join(names, ",")
+}
+
+// describeDecision returns the state of the SPRT as single word.
+func describeDecision(d stats.Decision) string {
+	switch d {
+	case stats.AcceptH0:
+		return "H0"
+	case stats.AcceptH1:
+		return "H1"
+	default:
+		return "running"
+	}
+}
+
+// event writes a log line for the event with the given key value pairs.
+// Values containing spaces are quoted.
+func (l logger) event(event string, fields ...any) {
+	line := "time=" + time.Now().Format(time.RFC3339) + " event=" + event
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		value := fmt.Sprint(fields[i+1])
+
+		if strings.ContainsAny(value, " \t\"") {
+			value = fmt.Sprintf("%q", value)
+		}
+
+		line += fmt.Sprintf(" %v=%s", fields[i], value)
+	}
+
+	fmt.Fprintln(os.Stdout, line)
+}
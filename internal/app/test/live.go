@@ -0,0 +1,237 @@
+package test
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+)
+
+// evalLimit is the evaluation in centipawns at which the sparkline is clipped.
+// Mate scores are shown at the limit.
+const evalLimit = 500
+
+// sparkWidth is the number of moves shown in the evaluation sparkline.
+const sparkWidth = 40
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// liveMsg is sent by a worker whenever a game starts, a move is played or the game ends.
+type liveMsg struct {
+	slot     int             // The worker playing the game.
+	white    int             // The index of the engine playing white.
+	position *chess.Position // The current position of the game.
+	engine   int             // The engine which played the last move or -1 at the start of a game.
+	info     uci.MoveInfo    // The search result of the last move.
+	result   *testflow.GameResult
+}
+
+// liveBatch are the live messages queued since the view applied the last batch.
+type liveBatch []liveMsg
+
+// liveFeed queues the live messages of the workers for the view.
+// Workers never block on the view: the view is signalled once for any number of
+// queued messages and applies them as a batch.
+type liveFeed struct {
+	mutex   sync.Mutex
+	pending []liveMsg
+	ready   chan struct{}
+}
+
+// newLiveFeed creates an empty feed.
+func newLiveFeed() *liveFeed {
+	return &liveFeed{
+		pending: make([]liveMsg, 0),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// push queues the message. A message at the start of a game drops the queued
+// messages of the previous game of the worker, as the view replaces that game anyway.
+func (f *liveFeed) push(msg liveMsg) {
+	f.mutex.Lock()
+
+	if msg.engine < 0 {
+		kept := f.pending[:0]
+
+		for _, m := range f.pending {
+			if m.slot != msg.slot {
+				kept = append(kept, m)
+			}
+		}
+
+		f.pending = kept
+	}
+
+	f.pending = append(f.pending, msg)
+	f.mutex.Unlock()
+
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the queued messages and empties the queue.
+func (f *liveFeed) take() liveBatch {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	batch := liveBatch(f.pending)
+	f.pending = make([]liveMsg, 0)
+
+	return batch
+}
+
+// liveGame is the state of a game in progress as shown in the games panel.
+type liveGame struct {
+	white    int
+	position *chess.Position
+	scores   [2]*uci.Score
+	depths   [2]int
+	evals    []int
+	result   *testflow.GameResult
+}
+
+// updateLive applies the state of a game in progress.
+// A message at the start of a game replaces the previous game of the worker.
+func (d *data) updateLive(msg liveMsg) {
+	if d.live == nil {
+		d.live = make(map[int]*liveGame)
+	}
+
+	game, ok := d.live[msg.slot]
+
+	if !ok || msg.engine < 0 {
+		game = &liveGame{white: msg.white, evals: make([]int, 0)}
+		d.live[msg.slot] = game
+	}
+
+	game.position = msg.position
+	game.result = msg.result
+
+	if msg.engine >= 0 && msg.result == nil {
+		score := msg.info.Score
+		game.scores[msg.engine] = &score
+		game.depths[msg.engine] = msg.info.Depth
+		game.evals = append(game.evals, whiteEval(score, msg.engine == msg.white))
+	}
+
+	d.keepSelection()
+}
+
+// liveSlots returns the workers with a game, in ascending order.
+func (d *data) liveSlots() []int {
+	slots := make([]int, 0, len(d.live))
+
+	for slot := range d.live {
+		slots = append(slots, slot)
+	}
+
+	sort.Ints(slots)
+	return slots
+}
+
+// selectGame moves the selection of the games panel by delta games, wrapping around at both ends.
+func (d *data) selectGame(delta int) {
+	slots := d.liveSlots()
+
+	if len(slots) == 0 {
+		return
+	}
+
+	idx := sort.SearchInts(slots, d.selected)
+	d.selected = slots[((idx+delta)%len(slots)+len(slots))%len(slots)]
+}
+
+// keepSelection selects the first game if the selected worker has no game.
+func (d *data) keepSelection() {
+	if _, ok := d.live[d.selected]; ok || len(d.live) == 0 {
+		return
+	}
+
+	d.selected = d.liveSlots()[0]
+}
+
+// selectedGame returns the game shown in the games panel and its position in the list of games.
+// If no game is selected, nil and -1 are returned.
+func (d *data) selectedGame() (*liveGame, int) {
+	for idx, slot := range d.liveSlots() {
+		if slot == d.selected {
+			return d.live[slot], idx
+		}
+	}
+
+	return nil, -1
+}
+
+// whiteEval converts a score from the perspective of the moving engine to
+// centipawns from the perspective of white, clipped to evalLimit.
+func whiteEval(score uci.Score, isWhite bool) int {
+	cp := score.Value
+
+	if score.Type == uci.Mate {
+		cp = evalLimit
+
+		if score.Value <= 0 {
+			cp = -evalLimit
+		}
+	}
+
+	if !isWhite {
+		cp = -cp
+	}
+
+	if cp > evalLimit {
+		return evalLimit
+	}
+
+	if cp < -evalLimit {
+		return -evalLimit
+	}
+
+	return cp
+}
+
+// formatScore returns the score in pawns, e.g. "+0.35", or the distance to mate, e.g. "#-3".
+func formatScore(score *uci.Score) string {
+	if score == nil {
+		return "-"
+	}
+
+	if score.Type == uci.Mate {
+		return fmt.Sprintf("#%d", score.Value)
+	}
+
+	return fmt.Sprintf("%+.2f", float64(score.Value)/100)
+}
+
+// formatDepth returns the search depth or "-" if the engine did not move yet.
+func formatDepth(depth int) string {
+	if depth == 0 {
+		return "-"
+	}
+
+	return strconv.Itoa(depth)
+}
+
+// sparkline draws the last width evaluations with one bar per move.
+func sparkline(evals []int, width int) string {
+	if len(evals) > width {
+		evals = evals[len(evals)-width:]
+	}
+
+	line := make([]rune, len(evals))
+	levels := len(sparkBars) - 1
+
+	for idx, eval := range evals {
+		level := (eval + evalLimit) * levels / (2 * evalLimit)
+		line[idx] = sparkBars[level]
+	}
+
+	return string(line)
+}
@@ -0,0 +1,351 @@
+package test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/clock"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+)
+
+// MatchConfig configures a local match between two engines.
+type MatchConfig struct {
+	Engines     [2]string // The engines as path to a binary or as "name@version" of an installed engine.
+	TimeControl string    // The time control in the format "[moves/]base[+increment]".
+	MoveTime    int       // The time per move in ms. Replaces the time control if set.
+	Depth       int       // The depth per move. Replaces the time control if set.
+	Games       int       // The number of games to play. Rounded up to an even number.
+	Concurrency int       // The number of games played at the same time. Derived from the hardware if zero.
+	Openings    string    // The path to a file with one FEN or EPD per line.
+	Hash        int       // The hash size of both engines in MB.
+	Threads     int       // The number of threads of both engines.
+	Options     []string  // Custom options in the format "engine:name=value" with the engine numbered from 1.
+}
+
+// score counts the wins, losses and draws from the perspective of the first engine.
+type score struct {
+	wins   int
+	losses int
+	draws  int
+}
+
+// RunMatch plays a match between two engines without a connection to the test server.
+// Every opening is played twice with swapped colors.
+// The result of each game and the final score are printed to stdout.
+func RunMatch(cfg MatchConfig) error {
+	ts := testService{discard: true}
+	d := &data{state: play}
+	names := [2]string{}
+	played := 0
+	total := score{}
+
+	for idx, spec := range cfg.Engines {
+		inst, path, err := resolveEngine(spec)
+
+		if err != nil {
+			return err
+		}
+
+		d.engines[idx] = *inst
+		d.binaries[idx] = path
+		names[idx] = spec
+	}
+
+	s, err := parseSearch(cfg.TimeControl, cfg.MoveTime, cfg.Depth)
+
+	if err != nil {
+		return err
+	}
+
+	if cfg.Openings != "" {
+		if d.openings, err = loadOpenings(cfg.Openings); err != nil {
+			return err
+		}
+	}
+
+	custom, err := parseEngineOptions(cfg.Options, len(cfg.Engines))
+
+	if err != nil {
+		return err
+	}
+
+	d.search = [2]search{s, s}
+
+	for idx := range d.options {
+		d.options[idx] = options{hash: cfg.Hash, threads: cfg.Threads, custom: custom[idx]}
+
+		if err := checkCustom(d.enginePath(idx), custom[idx]); err != nil {
+			return err
+		}
+	}
+
+	d.concurrency = cfg.Concurrency
+
+	if d.concurrency < 1 {
+		d.concurrency = ts.getConcurrency(d.options)
+	}
+
+	fmt.Printf("Started match between %s and %s with %d concurrent games\n", names[0], names[1], d.concurrency)
+
+	ts.onPair = func(_ pairJob, msg gameMsg) {
+		d.addResults(msg.results)
+
+		for _, res := range msg.results {
+			played++
+			total.add(res)
+
+			fmt.Println(describeResult(played, names, res))
+			fmt.Printf("Score of %s vs %s: %s\n", names[0], names[1], total.String())
+		}
+	}
+
+	if err, ok := ts.playPairs((cfg.Games+1)/2, d).(error); ok {
+		return err
+	}
+
+	elo := d.elo()
+
+	fmt.Printf("Finished match. Score of %s vs %s: %s\n", names[0], names[1], total.String())
+	fmt.Printf("Elo difference: %+.1f ± %.1f, LOS: %.1f %%\n", elo.Elo, (elo.Upper-elo.Lower)/2, d.games.LOS()*100)
+	return nil
+}
+
+// add counts the result of a game from the perspective of the first engine.
+// Failed games are not counted.
+func (s *score) add(res testflow.GameResult) {
+	if res.Failure != nil {
+		return
+	}
+
+	switch engineScore(res) {
+	case 0.5:
+		s.draws++
+	case 1:
+		s.wins++
+	default:
+		s.losses++
+	}
+}
+
+// engineScore returns the points of the first engine in the game.
+func engineScore(res testflow.GameResult) float64 {
+	switch {
+	case res.Outcome == testflow.Draw:
+		return 0.5
+	case (res.Outcome == testflow.WhiteWins) == (res.White == 0):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// points returns the score of the first engine, counting a draw as half a point.
+func (s score) points() float64 {
+	return float64(s.wins) + float64(s.draws)/2
+}
+
+// games returns the number of counted games.
+func (s score) games() int {
+	return s.wins + s.losses + s.draws
+}
+
+// String returns the score in the format "W - L - D [ratio] N".
+func (s score) String() string {
+	ratio := 0.0
+
+	if n := s.games(); n > 0 {
+		ratio = s.points() / float64(n)
+	}
+
+	return fmt.Sprintf("%d - %d - %d [%.3f] %d", s.wins, s.losses, s.draws, ratio, s.games())
+}
+
+// describeResult returns a line describing the finished game with the given number.
+func describeResult(num int, names [2]string, res testflow.GameResult) string {
+	white, black := names[res.White], names[(res.White+1)%2]
+	line := fmt.Sprintf("Finished game %d (%s vs %s): %s {%s}", num, white, black, res.Outcome, res.Reason)
+
+	if res.Failure != nil {
+		line += ": " + res.Failure.Message
+	}
+
+	return line
+}
+
+// resolveEngine returns the engine instance and the binary path for an engine specification.
+// An existing file is used as local binary. Otherwise the specification
+// is expected to be in the format "name@version" of an installed engine.
+func resolveEngine(spec string) (*mgmt.EngineInstance, string, error) {
+	if info, err := os.Stat(spec); err == nil && !info.IsDir() {
+		path, err := filepath.Abs(spec)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		return &mgmt.EngineInstance{Engine: filepath.Base(spec)}, path, nil
+	}
+
+	name, version, found := strings.Cut(spec, "@")
+
+	if !found {
+		return nil, "", errors.New("Engine is neither a binary nor in the format name@version: " + spec)
+	}
+
+	ver, err := mgmt.ParseVersion(version, mgmt.DotVersionStyle)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	inst, err := mgmt.FindInstalled(name, *ver)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return inst, inst.Path(), nil
+}
+
+// parseSearch returns the search settings for a match.
+// A fixed depth takes precedence over a fixed move time, which takes precedence over the time control.
+func parseSearch(tc string, movetime int, depth int) (search, error) {
+	if depth > 0 {
+		return search{mode: searchDepth, value: depth}, nil
+	}
+
+	if movetime > 0 {
+		return search{mode: searchTime, value: movetime}, nil
+	}
+
+	control, err := clock.Parse(tc)
+
+	if err != nil {
+		return search{}, err
+	}
+
+	return search{mode: searchClock, control: *control}, nil
+}
+
+// loadOpenings reads the starting positions from a file with one FEN or EPD per line.
+// Empty lines and lines starting with '#' are ignored.
+// EPD operations following the position are discarded.
+func loadOpenings(path string) ([]string, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	openings := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) < 4 {
+			return nil, errors.New("Invalid opening: " + line)
+		}
+
+		fen := strings.Join(fields[:4], " ")
+
+		if len(fields) >= 6 && isNumber(fields[4]) && isNumber(fields[5]) {
+			fen += " " + fields[4] + " " + fields[5]
+		}
+
+		pos, err := chess.ParseFEN(fen)
+
+		if err != nil {
+			return nil, err
+		}
+
+		openings = append(openings, pos.FEN())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(openings) == 0 {
+		return nil, errors.New("No openings found in " + path)
+	}
+
+	return openings, nil
+}
+
+// parseEngineOptions returns the custom options of each engine from a list of
+// options in the format "engine:name=value", where the engines are numbered from 1.
+func parseEngineOptions(specs []string, engines int) ([]map[string]string, error) {
+	custom := make([]map[string]string, engines)
+
+	for _, spec := range specs {
+		engine, option, found := strings.Cut(spec, ":")
+		name, value, hasValue := strings.Cut(option, "=")
+		name = strings.TrimSpace(name)
+
+		if !found || !hasValue || name == "" {
+			return nil, errors.New("Invalid engine option, expected engine:name=value: " + spec)
+		}
+
+		idx, err := strconv.Atoi(engine)
+
+		if err != nil || idx < 1 || idx > engines {
+			return nil, errors.New("Invalid engine of option, expected a number from 1 to " + strconv.Itoa(engines) + ": " + spec)
+		}
+
+		if custom[idx-1] == nil {
+			custom[idx-1] = make(map[string]string)
+		}
+
+		custom[idx-1][name] = strings.TrimSpace(value)
+	}
+
+	return custom, nil
+}
+
+// checkCustom launches the engine and verifies that it supports the custom options with the given values.
+func checkCustom(path string, custom map[string]string) error {
+	if len(custom) == 0 {
+		return nil
+	}
+
+	ifc, err := uci.NewFromExe(path, nil, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer ifc.Close()
+	defer ifc.Quit()
+
+	if !ifc.SetupTimeout(readyTimeout) {
+		return errors.New("Engine did not answer uci: " + path)
+	}
+
+	if _, problems := rejectedOptions(ifc, custom); len(problems) > 0 {
+		return errors.New("Engine rejected options: " + path + ": " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+)
+
+var engineOptions = []struct {
+	specs  []string
+	custom []map[string]string
+	valid  bool
+}{
+	{nil, []map[string]string{nil, nil}, true},
+	{[]string{"1:Contempt=10"}, []map[string]string{{"Contempt": "10"}, nil}, true},
+	{[]string{"2:Book File=/tmp/book.bin", "2:Ponder=false"}, []map[string]string{nil, {"Book File": "/tmp/book.bin", "Ponder": "false"}}, true},
+	{[]string{"1:Style="}, []map[string]string{{"Style": ""}, nil}, true},
+	{[]string{"Contempt=10"}, nil, false},
+	{[]string{"1:Contempt"}, nil, false},
+	{[]string{"1:=10"}, nil, false},
+	{[]string{"0:Contempt=10"}, nil, false},
+	{[]string{"3:Contempt=10"}, nil, false},
+	{[]string{"a:Contempt=10"}, nil, false},
+}
+
+func TestParseEngineOptions(t *testing.T) {
+	for _, io := range engineOptions {
+		custom, err := parseEngineOptions(io.specs, 2)
+
+		if !io.valid {
+			if err == nil {
+				t.Errorf("Expected %v to be rejected", io.specs)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Expected %v to be parsed, got %v", io.specs, err)
+		} else if !reflect.DeepEqual(custom, io.custom) {
+			t.Errorf("Expected %v for %v, got %v", io.custom, io.specs, custom)
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package test
 
 import (
+	"errors"
+	"sync/atomic"
 	"time"
 
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/clock"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/outbox"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/stats"
 	"github.com/charmbracelet/bubbles/stopwatch"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -19,12 +26,14 @@ const (
 	connect state = iota
 	wait
 	play
+	drain
 	quit
 )
 
 const (
 	searchTime searchMode = iota
 	searchDepth
+	searchClock
 )
 
 type model struct {
@@ -32,35 +41,196 @@ type model struct {
 	service *testService
 	data    *data
 	uptime  stopwatch.Model
+	updates chan tea.Msg
+	live    *liveFeed
 }
 
 type search struct {
-	mode  searchMode
-	value int
+	mode    searchMode
+	value   int
+	control clock.TimeControl
 }
 
 type options struct {
 	hash    int
 	threads int
+	custom  map[string]string
 }
 
 type data struct {
 	state       state
 	err         error
+	aborted     string
 	played      int
 	session     string
 	engines     [2]mgmt.EngineInstance
 	search      [2]search
 	options     [2]options
+	binaries    [2]string
+	openings    []string
 	concurrency int
+	cores       int
+	memory      int
+	games       stats.Trinomial
+	pairs       stats.Pentanomial
+	trace       bool
+	totals      [2]testflow.PerfTotals
+	perf        [2]testflow.PerfStats
+	live        map[int]*liveGame
+	selected    int
+}
+
+// equal returns true if both options configure an engine the same way.
+func (o options) equal(other options) bool {
+	if o.hash != other.hash || o.threads != other.threads || len(o.custom) != len(other.custom) {
+		return false
+	}
+
+	for name, value := range o.custom {
+		if v, ok := other.custom[name]; !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// enginePath returns the path to the binary of the engine with the given index.
+// Local binaries take precedence over installed engines.
+func (d *data) enginePath(idx int) string {
+	if d.binaries[idx] != "" {
+		return d.binaries[idx]
+	}
+
+	return d.engines[idx].Path()
+}
+
+// opening returns the starting position of the game pair with the given index.
+// The openings are used in order and repeated if there are more pairs than openings.
+// Without any openings the standard starting position is used.
+func (d *data) opening(pair int) string {
+	if len(d.openings) == 0 {
+		return chess.StartFEN
+	}
+
+	return d.openings[pair%len(d.openings)]
+}
+
+// addResults counts the results of a finished game pair from the perspective of the first engine.
+// Failed games are skipped and the pair is only added to the pentanomial
+// statistics if both games were played.
+func (d *data) addResults(results []testflow.GameResult) {
+	total := 0.0
+	counted := 0
+
+	for _, res := range results {
+		if res.Failure != nil {
+			continue
+		}
+
+		s := engineScore(res)
+		counted++
+		total += s
+		d.games.Add(s)
+	}
+
+	if counted == 2 {
+		d.pairs.Add(total)
+	}
+}
+
+// addSamples adds the performance samples of finished games to the statistics of the session.
+func (d *data) addSamples(games [][2][]testflow.MoveSample) {
+	for _, game := range games {
+		for idx := range game {
+			d.totals[idx].Add(game[idx]...)
+		}
+	}
+
+	d.perf = [2]testflow.PerfStats{d.totals[0].Stats(), d.totals[1].Stats()}
+}
+
+// summarize returns the performance statistics of both engines over the samples of all games.
+func summarize(games [][2][]testflow.MoveSample) [2]testflow.PerfStats {
+	samples := [2][]testflow.MoveSample{}
+
+	for _, game := range games {
+		for idx := range game {
+			samples[idx] = append(samples[idx], game[idx]...)
+		}
+	}
+
+	return [2]testflow.PerfStats{testflow.Summarize(samples[0]), testflow.Summarize(samples[1])}
+}
+
+// sprt returns the configured sequential probability ratio test.
+func (d *data) sprt() stats.SPRT {
+	cfg := conf.GetSPRTConfig()
+
+	return stats.SPRT{
+		Elo0:  cfg.Elo0,
+		Elo1:  cfg.Elo1,
+		Alpha: cfg.Alpha,
+		Beta:  cfg.Beta,
+	}
+}
+
+// elo returns the estimated Elo difference between both engines.
+// The pentanomial estimate is preferred as it accounts for the shared openings of a pair.
+func (d *data) elo() stats.Estimate {
+	if d.pairs.Pairs() > 0 {
+		return d.pairs.Elo()
+	}
+
+	return d.games.Elo()
 }
 
 func initModel() *model {
 	client, err := com.Connect(conf.GetTestServerConfig().GetURL(), testflow.NewFlow())
-	service := &testService{client: client}
+	updates := make(chan tea.Msg)
+	box, boxErr := outbox.Open(conf.GetOutbox())
+	service := &testService{
+		conn:     newConnection(client, err),
+		outbox:   box,
+		messages: make(chan any),
+		drain:    make(chan struct{}),
+		active:   &atomic.Int32{},
+	}
+
+	if err == nil {
+		go service.listen()
+	}
+
+	if err == nil && boxErr != nil {
+		err = errors.New("Could not open outbox: " + boxErr.Error())
+	}
+
+	cores, memory := service.availableResources()
+
+	service.onGame = func(d *data, msg gameMsg) {
+		id := service.reportID()
+		cmd, err := testflow.BuildGameReportCmd(id, d.session, msg.moves[0], msg.logs[0], msg.results[0], service.verbosity())
+
+		if err == nil {
+			err = service.deliver(id, cmd)
+		}
+
+		if err != nil {
+			updates <- err
+		}
+	}
+
+	service.onPair = func(_ pairJob, msg gameMsg) {
+		updates <- pairMsg{results: msg.results, samples: msg.samples}
+	}
+
+	live := newLiveFeed()
+	service.onMove = live.push
 
 	return &model{
 		service: service,
+		updates: updates,
+		live:    live,
 		uptime:  stopwatch.NewWithInterval(time.Second),
 		bar: progressbar.NewOptions(
 			-1,
@@ -73,6 +243,8 @@ func initModel() *model {
 			err:         err,
 			state:       connect,
 			concurrency: 1,
+			cores:       cores,
+			memory:      memory,
 		},
 	}
 }
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+)
+
+// formatPGN returns the game in PGN format.
+// The moves of the record are converted to standard algebraic notation and
+// the reason why the game ended is added as comment before the result.
+func formatPGN(event string, round int, names [2]string, record gameRecord, result testflow.GameResult) (string, error) {
+	var sb strings.Builder
+	game, err := chess.NewGame(record.start)
+
+	if err != nil {
+		return "", err
+	}
+
+	tag := func(key string, value string) {
+		value = strings.ReplaceAll(value, "\\", "\\\\")
+		value = strings.ReplaceAll(value, "\"", "\\\"")
+		sb.WriteString("[" + key + " \"" + value + "\"]\n")
+	}
+
+	tag("Event", event)
+	tag("Site", "?")
+	tag("Date", time.Now().Format("2006.01.02"))
+	tag("Round", strconv.Itoa(round))
+	tag("White", names[result.White])
+	tag("Black", names[(result.White+1)%2])
+	tag("Result", string(result.Outcome))
+
+	if record.start != chess.StartFEN {
+		tag("SetUp", "1")
+		tag("FEN", record.start)
+	}
+
+	tokens := make([]string, 0, len(record.moves)*2+2)
+
+	for idx, uci := range record.moves {
+		pos := game.Position()
+		move, err := pos.ParseMove(uci)
+
+		if err != nil {
+			return "", err
+		}
+
+		if pos.Turn() == chess.White {
+			tokens = append(tokens, strconv.Itoa(pos.Fullmove())+".")
+		} else if idx == 0 {
+			tokens = append(tokens, strconv.Itoa(pos.Fullmove())+"...")
+		}
+
+		tokens = append(tokens, pos.SAN(move))
+		game.Move(uci)
+	}
+
+	if result.Reason != "" {
+		tokens = append(tokens, "{"+result.Reason+"}")
+	}
+
+	tokens = append(tokens, string(result.Outcome))
+	sb.WriteString("\n")
+	line := 0
+
+	for idx, token := range tokens {
+		if idx > 0 && line+len(token)+1 > 80 {
+			sb.WriteString("\n")
+			line = 0
+		} else if idx > 0 {
+			sb.WriteString(" ")
+			line++
+		}
+
+		sb.WriteString(token)
+		line += len(token)
+	}
+
+	return sb.String() + "\n\n", nil
+}
@@ -0,0 +1,210 @@
+package test
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+)
+
+// readyTimeout is the time an engine has to answer isready before it is restarted.
+const readyTimeout = 10 * time.Second
+
+// hangTimeout is the time an engine may exceed its search limits before it is considered hung.
+const hangTimeout = 10 * time.Second
+
+// depthTimeout is the time a search with a fixed depth may take before the engine is considered hung.
+const depthTimeout = 5 * time.Minute
+
+// budgetMoves is the number of moves the remaining time of a clock without a move
+// limit is expected to last, which defines the time allotted for a single move.
+const budgetMoves = 30
+
+// failureOutput is the number of lines exchanged with an engine which are kept as diagnostics of a failed game.
+const failureOutput = 20
+
+// minFailureSample is the number of games which have to be played before a batch is aborted due to failures.
+const minFailureSample = 4
+
+// enginePool keeps the engine processes of a worker alive between games.
+// Each of the two engine slots of a game has its own process, which is reused
+// as long as the binary and options do not change and the engine did not
+// crash or time out. All engines of the pool are pinned to the CPUs of the pool, if any.
+// The slot identifies the worker owning the pool.
+type enginePool struct {
+	engines [2]*pooledEngine
+	cpus    []int
+	slot    int
+}
+
+// pooledEngine is an engine process owned by an enginePool.
+// The log of the engine is redirected to the game it currently plays.
+type pooledEngine struct {
+	ifc     *uci.UCI
+	path    string
+	options options
+	mutex   sync.Mutex
+	log     *[]testflow.LogEntry
+	limit   int
+}
+
+// acquire returns the engine for the slot with the given index, prepared for a new game.
+// The engine writes its communication to log. If limit is greater than zero, only
+// the last limit entries are kept, which are still sufficient for failure diagnostics.
+// A running engine is reused after sending ucinewgame and waiting for isready.
+// The engine is restarted if its binary or options changed or it is not ready in time.
+func (p *enginePool) acquire(idx int, data *data, log *[]testflow.LogEntry, limit int) (*uci.UCI, error) {
+	path := data.enginePath(idx)
+	opts := data.options[idx]
+
+	if e := p.engines[idx]; e != nil {
+		if e.path == path && e.options.equal(opts) {
+			e.setLog(log, limit)
+			e.ifc.Start()
+
+			if e.ifc.WaitReady(readyTimeout) {
+				return e.ifc, nil
+			}
+		}
+
+		e.close()
+		p.engines[idx] = nil
+	}
+
+	e := &pooledEngine{path: path, options: opts, log: log, limit: limit}
+	ifc, err := uci.NewFromExePinned(path, p.cpus, e.callback("send"), e.callback("recv"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.ifc = ifc
+
+	if !ifc.SetupTimeout(readyTimeout) {
+		e.close()
+		return nil, errors.New("Engine did not answer uci: " + path)
+	}
+
+	if opt := ifc.GetOptionConfig("hash"); opt != nil {
+		ifc.SetOption(opt.Response(strconv.Itoa(opts.hash)))
+	}
+
+	if opt := ifc.GetOptionConfig("threads"); opt != nil {
+		ifc.SetOption(opt.Response(strconv.Itoa(opts.threads)))
+	}
+
+	for _, name := range customNames(opts.custom) {
+		if opt := ifc.GetOptionConfig(name); opt != nil {
+			ifc.SetOption(opt.Response(opts.custom[name]))
+		}
+	}
+
+	ifc.Start()
+
+	if !ifc.WaitReady(readyTimeout) {
+		e.close()
+		return nil, errors.New("Engine did not become ready: " + path)
+	}
+
+	p.engines[idx] = e
+	return ifc, nil
+}
+
+// fail quits the engine of the slot after a crash or timeout, so it is restarted before the next game.
+func (p *enginePool) fail(idx int) {
+	if e := p.engines[idx]; e != nil {
+		e.close()
+		p.engines[idx] = nil
+	}
+}
+
+// release detaches the engines from the logs of the finished game.
+func (p *enginePool) release() {
+	for _, e := range p.engines {
+		if e != nil {
+			e.setLog(nil, 0)
+		}
+	}
+}
+
+// close quits all engine processes of the pool.
+func (p *enginePool) close() {
+	for idx, e := range p.engines {
+		if e != nil {
+			e.close()
+			p.engines[idx] = nil
+		}
+	}
+}
+
+// rejectedOptions returns the names of the custom options which the engine does not
+// support or whose values it does not accept, together with a description of each problem.
+func rejectedOptions(ifc *uci.UCI, custom map[string]string) ([]string, []string) {
+	names := make([]string, 0)
+	problems := make([]string, 0)
+
+	for _, name := range customNames(custom) {
+		opt := ifc.GetOptionConfig(name)
+
+		if opt == nil {
+			names = append(names, name)
+			problems = append(problems, "Unsupported option: "+name)
+		} else if err := opt.Validate(custom[name]); err != nil {
+			names = append(names, name)
+			problems = append(problems, err.Error())
+		}
+	}
+
+	return names, problems
+}
+
+// customNames returns the names of the custom options in alphabetical order,
+// so options are always sent in the same order.
+func customNames(custom map[string]string) []string {
+	names := make([]string, 0, len(custom))
+
+	for name := range custom {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (e *pooledEngine) callback(kind string) func(string) {
+	return func(line string) {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		if e.log == nil {
+			return
+		}
+
+		if e.limit > 0 && len(*e.log) >= e.limit {
+			*e.log = (*e.log)[1:]
+		}
+
+		*e.log = append(*e.log, testflow.LogEntry{
+			Type:  kind,
+			Value: line,
+		})
+	}
+}
+
+func (e *pooledEngine) setLog(log *[]testflow.LogEntry, limit int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.log = log
+	e.limit = limit
+}
+
+func (e *pooledEngine) close() {
+	e.setLog(nil, 0)
+	e.ifc.Quit()
+	e.ifc.Close()
+}
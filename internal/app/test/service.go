@@ -5,9 +5,16 @@ import (
 	"math"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/clock"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/outbox"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/sys"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
@@ -24,36 +31,248 @@ type startMsg struct {
 	search  [2]search
 	batch   int
 	options [2]options
+	trace   bool
 }
 
 type gameMsg struct {
 	gameCount int
 	moves     []testflow.GameMoveHistory
 	logs      []testflow.Log
+	results   []testflow.GameResult
+	records   []gameRecord
+	samples   [][2][]testflow.MoveSample
+	aborted   string // The reason why the batch was aborted early, if it was.
+}
+
+type suiteErrorMsg struct {
+	cmd testflow.SuiteErrorCmd
+}
+
+type pairMsg struct {
+	results []testflow.GameResult
+	samples [][2][]testflow.MoveSample
+}
+
+type gameRecord struct {
+	start string
+	moves []string
+}
+
+type pairJob struct {
+	data *data
+	pair int
+}
+
+type pairResult struct {
+	job pairJob
+	msg gameMsg
 }
 
 type testService struct {
-	client *com.Client
+	conn     *connection
+	outbox   *outbox.Outbox
+	messages chan any
+	drain    chan struct{}
+	active   *atomic.Int32
+	onGame   func(*data, gameMsg)
+	onPair   func(pairJob, gameMsg)
+	onMove   func(liveMsg)
+	discard  bool // Whether the hooks consume the finished pairs, so their moves and logs are not collected.
+}
+
+// track adds delta to the number of games in progress, if the service counts them.
+func (ts testService) track(delta int32) {
+	if ts.active != nil {
+		ts.active.Add(delta)
+	}
+}
+
+// remaining returns the number of games in progress, including the games of started pairs
+// which did not start yet.
+func (ts testService) remaining() int {
+	if ts.active == nil {
+		return 0
+	}
+
+	return int(ts.active.Load())
+}
+
+// listen forwards the messages of the server to the service.
+// Acknowledged reports are removed from the outbox as soon as the acknowledgement arrives.
+// If the connection drops, it is restored and the worker registers again.
+func (ts testService) listen() {
+	for {
+		client, _ := ts.conn.current()
+
+		for err := error(nil); err == nil; {
+			select {
+			case msg := <-client.Messages:
+				if !ts.acknowledge(msg) {
+					ts.messages <- msg
+				}
+			case err = <-client.Errors:
+			}
+		}
+
+		if !ts.conn.drop() {
+			return
+		}
+
+		if err := ts.reconnect(); err != nil {
+			ts.conn.abandon(err)
+			return
+		}
+	}
+}
+
+// acknowledge removes the report from the outbox if the message is an acknowledgement.
+// It returns whether the message was an acknowledgement.
+func (ts testService) acknowledge(msg any) bool {
+	ack, ok := msg.(testflow.AckMsg)
+
+	if ok && ts.outbox != nil {
+		ts.outbox.Remove(ack.Id)
+	}
+
+	return ok
+}
+
+// reconnect restores the connection to the test server with an increasing delay
+// between the attempts. The worker registers again and all reports which were
+// not acknowledged yet are replayed.
+func (ts testService) reconnect() error {
+	cfg := conf.GetReconnectConfig()
+	delay := cfg.InitialDelay
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		time.Sleep(delay)
+		client, err := com.Connect(conf.GetTestServerConfig().GetURL(), testflow.NewFlow())
+
+		if err == nil {
+			if err = ts.reregister(client); err == nil {
+				if !ts.conn.replace(client) {
+					client.Close()
+					return nil
+				}
+
+				if err, ok := ts.replay().(error); ok {
+					return err
+				}
+
+				return nil
+			}
+
+			client.Close()
+		}
+
+		if delay *= 2; delay > cfg.MaxDelay {
+			delay = cfg.MaxDelay
+		}
+	}
+
+	return errors.New("Connection to test server lost")
+}
+
+// reregister registers the worker with a new client and waits for the confirmation.
+func (ts testService) reregister(client *com.Client) error {
+	client.Commands <- ts.registerCmd()
+
+	for {
+		select {
+		case msg := <-client.Messages:
+			if _, ok := msg.(testflow.RegisteredMsg); ok {
+				return nil
+			}
+
+			ts.acknowledge(msg)
+		case err := <-client.Errors:
+			return err
+		}
+	}
+}
+
+// reportID returns a new id for a report.
+func (ts testService) reportID() string {
+	if ts.outbox == nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	return ts.outbox.NextID()
+}
+
+// verbosity returns the configured verbosity of reports.
+// Unknown verbosities report everything.
+func (ts testService) verbosity() testflow.Verbosity {
+	v, _ := testflow.ParseVerbosity(conf.GetReportConfig().Verbosity)
+	return v
+}
+
+// deliver stores the report in the outbox before sending it to the server.
+// The report stays in the outbox until the server acknowledged its id.
+// An error is returned if the report could not be stored.
+func (ts testService) deliver(id string, cmd com.Command) error {
+	if ts.outbox != nil {
+		if err := ts.outbox.Put(id, cmd); err != nil {
+			return errors.New("Could not store report: " + err.Error())
+		}
+	}
+
+	ts.conn.send(cmd)
+	return nil
+}
+
+// replay sends all reports which were not acknowledged yet, e.g. because the
+// connection dropped or the worker exited before the acknowledgement arrived.
+func (ts testService) replay() tea.Msg {
+	if ts.outbox == nil {
+		return nil
+	}
+
+	entries, err := ts.outbox.Pending()
+
+	if err != nil {
+		return errors.New("Could not read outbox: " + err.Error())
+	}
+
+	for _, entry := range entries {
+		if !ts.conn.send(entry) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// registerCmd returns the command which registers the worker with its resource limits.
+func (ts testService) registerCmd() com.Command {
+	res := conf.GetResourceConfig()
+
+	return testflow.BuildRegisterCmd(testflow.Limits{
+		Concurrency:  res.Concurrency,
+		MaxCores:     res.MaxCores,
+		MaxMemory:    res.MaxMemory,
+		ReserveCores: res.ReserveCores,
+	})
 }
 
 func (ts testService) register() tea.Msg {
-	ts.client.Commands <- testflow.BuildRegisterCmd()
+	ts.conn.send(ts.registerCmd())
 
 	select {
-	case resp := <-ts.client.Messages:
+	case resp := <-ts.messages:
 		if rm, ok := resp.(testflow.RegisteredMsg); ok {
 			return registerMsg{id: rm.Id}
 		} else {
 			return errors.New("did not receive registration confirmation")
 		}
-	case err := <-ts.client.Errors:
-		return err
+	case <-ts.conn.failed:
+		return ts.conn.reason()
 	}
 }
 
 func (ts testService) awaitGameStart() tea.Msg {
 	select {
-	case resp := <-ts.client.Messages:
+	case resp := <-ts.messages:
 		if sm, ok := resp.(testflow.StartMsg); ok {
 			if len(sm.Suite.Engines) != 2 {
 				return errors.New("did not receive two engines")
@@ -65,6 +284,7 @@ func (ts testService) awaitGameStart() tea.Msg {
 				search:  [2]search{},
 				batch:   sm.RecommendedBatchSize,
 				options: [2]options{},
+				trace:   sm.Suite.Trace,
 			}
 
 			for idx, e := range sm.Suite.Engines {
@@ -85,213 +305,664 @@ func (ts testService) awaitGameStart() tea.Msg {
 					}
 				}
 
+				var control clock.TimeControl
+
 				switch e.Time.Type {
 				case "depth":
 					mode = searchDepth
 				case "movetime":
 					mode = searchTime
+				case "clock":
+					mode = searchClock
+					tc, err := clock.Parse(e.Time.Control)
+
+					if err != nil {
+						return err
+					}
+
+					control = *tc
 				default:
 					return errors.New("invalid search type")
 				}
 
 				result.engines[idx] = *engine
 				result.search[idx] = search{
-					mode:  mode,
-					value: e.Time.Value,
+					mode:    mode,
+					value:   e.Time.Value,
+					control: control,
 				}
 				result.options[idx] = options{
 					hash:    e.Options.HashSize,
 					threads: e.Options.Threads,
+					custom:  make(map[string]string),
+				}
+
+				for name, value := range e.Options.Custom {
+					result.options[idx].custom[name] = string(value)
 				}
 			}
 
+			if msg := ts.checkOptions(result); msg != nil {
+				return msg
+			}
+
 			return result
 		} else {
 			return errors.New("did not receive start confirmation")
 		}
-	case err := <-ts.client.Errors:
-		return err
+	case <-ts.conn.failed:
+		return ts.conn.reason()
+	}
+}
+
+// checkOptions launches the engines of a started session and verifies that they
+// support the custom options of the suite with the given values.
+// If an engine can not be launched or rejects an option, a suiteErrorMsg is returned, otherwise nil.
+func (ts testService) checkOptions(msg startMsg) tea.Msg {
+	for idx, engine := range msg.engines {
+		custom := msg.options[idx].custom
+
+		if len(custom) == 0 {
+			continue
+		}
+
+		ifc, err := uci.NewFromExe(engine.Path(), nil, nil)
+
+		if err != nil {
+			return suiteErrorMsg{cmd: testflow.BuildSuiteErrorCmd(msg.session, idx, err.Error(), []string{})}
+		}
+
+		if !ifc.SetupTimeout(readyTimeout) {
+			ifc.Close()
+			return suiteErrorMsg{cmd: testflow.BuildSuiteErrorCmd(msg.session, idx, "Engine did not answer uci", []string{})}
+		}
+
+		rejected, problems := rejectedOptions(ifc, custom)
+		ifc.Quit()
+		ifc.Close()
+
+		if len(rejected) > 0 {
+			return suiteErrorMsg{cmd: testflow.BuildSuiteErrorCmd(msg.session, idx, strings.Join(problems, "; "), rejected)}
+		}
+	}
+
+	return nil
+}
+
+func (ts testService) searchLimits(data *data, clocks [2]*clock.Clock, engineIdx int, white int) uci.Limits {
+	s := data.search[engineIdx]
+
+	switch s.mode {
+	case searchDepth:
+		return uci.Limits{Depth: s.value}
+	case searchClock:
+		remaining := func(c *clock.Clock) int {
+			if c == nil {
+				return 0
+			}
+
+			return int(c.Remaining().Milliseconds())
+		}
+
+		increment := func(c *clock.Clock) int {
+			if c == nil {
+				return 0
+			}
+
+			return int(c.Increment().Milliseconds())
+		}
+
+		black := (white + 1) % 2
+
+		return uci.Limits{
+			WTime:     remaining(clocks[white]),
+			BTime:     remaining(clocks[black]),
+			WInc:      increment(clocks[white]),
+			BInc:      increment(clocks[black]),
+			MovesToGo: clocks[engineIdx].MovesToGo(),
+		}
+	default:
+		return uci.Limits{MoveTime: s.value}
 	}
 }
 
-func (ts testService) isGameOver(info *uci.MoveInfo) bool {
-	noMove := info.Move == "(none)"
-	mate := info.Score.Type == uci.Mate && info.Score.Value == 1
+// searchTimeout returns the time after which a search with the given limits is considered hung.
+// With a clock the engine may use its whole remaining time. Searches with a fixed depth get depthTimeout.
+func (ts testService) searchTimeout(limits uci.Limits, engineIdx int, white int) time.Duration {
+	ms := limits.MoveTime
+
+	if remaining, _ := remainingTime(limits, engineIdx, white); remaining > 0 {
+		ms = remaining
+	}
 
-	return noMove || mate
+	if ms == 0 {
+		return depthTimeout
+	}
+
+	return time.Duration(ms)*time.Millisecond + hangTimeout
 }
 
+// allottedTime returns the time in ms the engine is expected to use for a search with the given limits.
+// With a clock this is the share of the remaining time for a single move, i.e. the remaining time divided
+// by the moves to go, or by budgetMoves without a move limit, plus the increment.
+// Searches with a fixed depth are not limited, so zero is returned.
+func (ts testService) allottedTime(limits uci.Limits, engineIdx int, white int) int {
+	remaining, inc := remainingTime(limits, engineIdx, white)
+
+	if remaining == 0 {
+		return limits.MoveTime
+	}
+
+	moves := limits.MovesToGo
+
+	if moves <= 0 {
+		moves = budgetMoves
+	}
+
+	if budget := remaining/moves + inc; budget < remaining {
+		return budget
+	}
+
+	return remaining
+}
+
+// remainingTime returns the remaining time and the increment in ms of the engine
+// to move, or zero if the search is not limited by a clock.
+func remainingTime(limits uci.Limits, engineIdx int, white int) (int, int) {
+	if engineIdx == white && limits.WTime > 0 {
+		return limits.WTime, limits.WInc
+	} else if engineIdx != white && limits.BTime > 0 {
+		return limits.BTime, limits.BInc
+	}
+
+	return 0, 0
+}
+
+// getConcurrency returns the number of games which can be played at the same time.
+// The number is derived from the available cores and memory within the configured
+// resource limits, unless the concurrency is fixed by the configuration.
 func (ts testService) getConcurrency(options [2]options) int {
-	device, _ := sys.DeviceInfo()
-	cores := runtime.NumCPU()
-	threads := int(math.Max(float64(options[0].threads), float64(options[1].threads)))
+	res := conf.GetResourceConfig()
+
+	if res.Concurrency > 0 {
+		return res.Concurrency
+	}
+
+	cores, availableMemory := ts.availableResources()
+	threads := int(math.Max(math.Max(float64(options[0].threads), float64(options[1].threads)), 1))
 	requiredMemory := options[0].hash + options[1].hash + 512
-	availableMemory := device.Memory / 1024 / 1024
 	cpuLimit := cores / threads
 	memLimit := availableMemory / requiredMemory
 	limit := int(math.Min(float64(cpuLimit), float64(memLimit)))
 
-	return limit
+	return int(math.Max(float64(limit), 1))
+}
+
+// availableResources returns the number of cores and the memory in MB the games may use.
+func (ts testService) availableResources() (int, int) {
+	res := conf.GetResourceConfig()
+	device, _ := sys.DeviceInfo()
+	cores := runtime.NumCPU()
+	memory := device.Memory / 1024 / 1024
+
+	if res.MaxCores > 0 && res.MaxCores < cores {
+		cores = res.MaxCores
+	}
+
+	if res.MaxMemory > 0 && res.MaxMemory < memory {
+		memory = res.MaxMemory
+	}
+
+	cores -= res.ReserveCores
+
+	if cores < 1 {
+		cores = 1
+	}
+
+	return cores, memory
+}
+
+func (ts testService) gameResult(game *chess.Game, white int, loser int, reason string, failure *testflow.Failure) testflow.GameResult {
+	result := testflow.GameResult{
+		White:   white,
+		Outcome: testflow.Draw,
+		Reason:  reason,
+	}
+
+	if failure != nil {
+		result.Outcome = testflow.Unterminated
+		result.Reason = "engine " + string(failure.Kind)
+		result.Failure = failure
+		return result
+	}
+
+	black := (white + 1) % 2
+	term := game.Termination()
+
+	if loser < 0 && term == chess.Checkmate {
+		loser = white
+
+		if game.Position().Turn() == chess.Black {
+			loser = black
+		}
+	}
+
+	switch loser {
+	case white:
+		result.Outcome = testflow.BlackWins
+	case black:
+		result.Outcome = testflow.WhiteWins
+	}
+
+	if result.Reason == "" {
+		result.Reason = string(term)
+	}
+
+	if result.Reason == "" {
+		result.Reason = "move limit"
+	}
+
+	return result
+}
+
+// failure returns the diagnostics of a failed game including the last lines exchanged with the engine.
+func (ts testService) failure(kind testflow.FailureKind, engineIdx int, message string, log []testflow.LogEntry) *testflow.Failure {
+	output := make([]string, 0, failureOutput)
+
+	if len(log) > failureOutput {
+		log = log[len(log)-failureOutput:]
+	}
+
+	for _, entry := range log {
+		prefix := "< "
+
+		if entry.Type == "send" {
+			prefix = "> "
+		}
+
+		output = append(output, prefix+entry.Value)
+	}
+
+	return &testflow.Failure{
+		Kind:    kind,
+		Engine:  engineIdx,
+		Message: message,
+		Output:  output,
+	}
 }
 
 func (ts testService) dispatchGames(batch int, data *data) tea.Msg {
 	cap := data.concurrency
-	numGames := cap
-	finished := 0
-	active := 0
-	gameChan := make(chan gameMsg)
-	errChan := make(chan error)
-	result := gameMsg{}
 
-	if numGames < 1 {
-		numGames = 1
+	if cap < 1 {
+		cap = 1
 	}
 
+	numGames := cap
+
 	for numGames < batch {
 		numGames += cap
 	}
 
-	for i := 0; i < cap; i++ {
-		active++
-		go ts.awaitGame(gameChan, errChan, data)
+	return ts.playPairs(numGames, data)
+}
+
+func (ts testService) playPairs(numGames int, data *data) tea.Msg {
+	jobs := make([]pairJob, numGames)
+
+	for i := range jobs {
+		jobs[i] = pairJob{data: data, pair: i}
 	}
 
-	for finished < numGames {
+	return ts.playJobs(jobs, data.concurrency)
+}
+
+// playJobs plays the game pairs of all jobs on cap workers.
+// Each worker owns an engine pool, so the engine processes are reused
+// across the games a worker plays and quit when the jobs are done.
+// Once the service drains, no further jobs are started and the results
+// of the jobs in progress are returned.
+// If too many games fail, the batch is aborted in the same way and the
+// reason is returned with the results of the finished games.
+// If the service discards finished pairs, only their results are returned.
+func (ts testService) playJobs(jobs []pairJob, cap int) tea.Msg {
+	numGames := len(jobs)
+	started := 0
+	finished := 0
+	failed := 0
+	played := 0
+	draining := false
+	drain := ts.drain
+	queue := make(chan pairJob)
+	gameChan := make(chan pairResult, numGames)
+	errChan := make(chan error, numGames)
+	result := gameMsg{}
+
+	if cap < 1 {
+		cap = 1
+	}
+
+	defer close(queue)
+
+	sets := ts.cpuSets(jobs, cap)
+
+	for i := 0; i < cap && i < numGames; i++ {
+		var cpus []int
+
+		if sets != nil {
+			cpus = sets[i]
+		}
+
+		go ts.runWorker(i, cpus, queue, gameChan, errChan)
+	}
+
+	for finished < started || (started < numGames && !draining) {
+		var next chan pairJob
+		var job pairJob
+
+		if started < numGames && !draining {
+			next = queue
+			job = jobs[started]
+		}
+
 		select {
-		case msg := <-gameChan:
+		case next <- job:
+			started++
+			ts.track(2)
+		case <-drain:
+			draining = true
+			drain = nil
+		case res := <-gameChan:
+			msg := res.msg
 			finished++
-			active--
 			result.gameCount += msg.gameCount
-			result.moves = append(result.moves, msg.moves...)
-			result.logs = append(result.logs, msg.logs...)
+			result.results = append(result.results, msg.results...)
+
+			if !ts.discard {
+				result.moves = append(result.moves, msg.moves...)
+				result.logs = append(result.logs, msg.logs...)
+				result.records = append(result.records, msg.records...)
+				result.samples = append(result.samples, msg.samples...)
+			}
+
+			if ts.onPair != nil {
+				ts.onPair(res.job, msg)
+			}
+
+			for _, r := range msg.results {
+				played++
+
+				if r.Failure != nil {
+					failed++
+				}
+			}
+
+			if result.aborted == "" && played >= minFailureSample && float64(failed)/float64(played) > conf.GetMaxFailureRate() {
+				result.aborted = "Aborted games: " + strconv.Itoa(failed) + " of " + strconv.Itoa(played) + " games failed"
+				draining = true
+			}
 		case err := <-errChan:
 			return err
 		}
+	}
+
+	return result
+}
+
+// cpuSets returns a disjoint set of CPUs for each of the cap workers, sized to the
+// highest number of threads an engine of the jobs uses.
+// If CPU affinity is disabled or not supported, nil is returned.
+func (ts testService) cpuSets(jobs []pairJob, cap int) [][]int {
+	if !conf.GetCPUAffinity() {
+		return nil
+	}
+
+	cores, err := sys.CPUTopology()
+
+	if err != nil {
+		return nil
+	}
 
-		if finished+active < numGames {
-			active++
-			go ts.awaitGame(gameChan, errChan, data)
+	threads := 1
+
+	for _, job := range jobs {
+		for _, opts := range job.data.options {
+			threads = int(math.Max(float64(threads), float64(opts.threads)))
 		}
 	}
 
-	return result
+	return sys.PartitionCPUs(cores, int(math.Min(float64(cap), float64(len(jobs)))), threads)
 }
 
-func (ts testService) awaitGame(msg chan gameMsg, err chan error, data *data) {
-	resp := ts.playGamePair(data)
+// runWorker plays the jobs it receives one after another until the queue is closed.
+// The engines of the worker are pinned to the given CPUs.
+func (ts testService) runWorker(slot int, cpus []int, queue chan pairJob, msg chan pairResult, err chan error) {
+	pool := &enginePool{cpus: cpus, slot: slot}
+	defer pool.close()
 
-	switch resp := resp.(type) {
-	case error:
-		err <- resp
-	default:
-		msg <- resp.(gameMsg)
+	for job := range queue {
+		resp := ts.playGamePair(pool, job.data, job.pair)
+
+		switch resp := resp.(type) {
+		case error:
+			err <- resp
+		default:
+			msg <- pairResult{job: job, msg: resp.(gameMsg)}
+		}
 	}
 }
 
-func (ts testService) playGamePair(data *data) tea.Msg {
+func (ts testService) playGamePair(pool *enginePool, data *data, pair int) tea.Msg {
 	result := gameMsg{
 		gameCount: 0,
 		moves:     []testflow.GameMoveHistory{},
 		logs:      []testflow.Log{},
+		results:   []testflow.GameResult{},
+		records:   []gameRecord{},
+		samples:   [][2][]testflow.MoveSample{},
 	}
+	opening := data.opening(pair)
 
-	resp1 := ts.playGame(data, false)
+	resp1 := ts.playGame(pool, data, false, opening)
+	ts.track(-1)
 
 	switch resp1 := resp1.(type) {
 	case error:
+		ts.track(-1)
 		return resp1
 	case gameMsg:
+		if ts.onGame != nil {
+			ts.onGame(data, resp1)
+		}
+
 		result.gameCount += resp1.gameCount
-		result.moves = append(result.moves, resp1.moves...)
-		result.logs = append(result.logs, resp1.logs...)
+		result.results = append(result.results, resp1.results...)
+		result.records = append(result.records, resp1.records...)
+		result.samples = append(result.samples, resp1.samples...)
+
+		if !ts.discard {
+			result.moves = append(result.moves, resp1.moves...)
+			result.logs = append(result.logs, resp1.logs...)
+		}
 	}
 
-	resp2 := ts.playGame(data, true)
+	resp2 := ts.playGame(pool, data, true, opening)
+	ts.track(-1)
 
 	switch resp2 := resp2.(type) {
 	case error:
 		return resp2
 	case gameMsg:
+		if ts.onGame != nil {
+			ts.onGame(data, resp2)
+		}
+
 		result.gameCount += resp2.gameCount
-		result.moves = append(result.moves, resp2.moves...)
-		result.logs = append(result.logs, resp2.logs...)
+		result.results = append(result.results, resp2.results...)
+		result.records = append(result.records, resp2.records...)
+		result.samples = append(result.samples, resp2.samples...)
+
+		if !ts.discard {
+			result.moves = append(result.moves, resp2.moves...)
+			result.logs = append(result.logs, resp2.logs...)
+		}
 	}
 
 	return result
 }
 
-func (ts testService) playGame(data *data, swapColor bool) tea.Msg {
+// publish sends the state of the game played by the pool to the onMove hook, if any.
+func (ts testService) publish(pool *enginePool, game *chess.Game, white int, engineIdx int, info *uci.MoveInfo, result *testflow.GameResult) {
+	if ts.onMove == nil {
+		return
+	}
+
+	msg := liveMsg{
+		slot:     pool.slot,
+		white:    white,
+		position: game.Position(),
+		engine:   engineIdx,
+		result:   result,
+	}
+
+	if info != nil {
+		msg.info = *info
+	}
+
+	ts.onMove(msg)
+}
+
+func (ts testService) setPosition(u *uci.UCI, opening string, moves []string) {
+	if opening == chess.StartFEN {
+		u.SetMoves(moves...)
+	} else {
+		u.SetPosition(opening, moves...)
+	}
+}
+
+func (ts testService) playGame(pool *enginePool, data *data, swapColor bool, opening string) tea.Msg {
 	ifc := [2]*uci.UCI{}
 	maxMoves := 250
 	moves := make([]string, 0, maxMoves)
-	info := &uci.MoveInfo{}
 	moveIdx := 0
 	engineIdx := 0
+	white := 0
+	loser := -1
+	reason := ""
+	clocks := [2]*clock.Clock{}
+	var failure *testflow.Failure
 	history := make([][]uci.MoveInfo, 2)
 	logs := make([][]testflow.LogEntry, 2)
+	samples := [2][]testflow.MoveSample{}
+	game, err := chess.NewGame(opening)
 
-	for idx, e := range data.engines {
-		logs[idx] = make([]testflow.LogEntry, 0, 1024)
-		log := &logs[idx]
+	if err != nil {
+		return err
+	}
 
-		scb := func(send string) {
-			*log = append(*log, testflow.LogEntry{
-				Type:  "send",
-				Value: send,
-			})
-		}
+	defer pool.release()
 
-		rcb := func(recv string) {
-			*log = append(*log, testflow.LogEntry{
-				Type:  "recv",
-				Value: recv,
-			})
-		}
+	logLimit := 0
+
+	if v := ts.verbosity(); v == testflow.VerbosityNone || v == testflow.VerbosityMoves {
+		logLimit = failureOutput
+	}
 
-		u, err := uci.New(&e, scb, rcb)
+	for idx := range data.engines {
+		logs[idx] = make([]testflow.LogEntry, 0)
+		u, err := pool.acquire(idx, data, &logs[idx], logLimit)
 
 		if err != nil {
-			return err
+			failure = ts.failure(testflow.LaunchFailure, idx, err.Error(), logs[idx])
+			break
 		}
 
 		ifc[idx] = u
 	}
 
 	if swapColor {
-		engineIdx = 1
+		white = 1
+	}
+
+	engineIdx = white
+
+	if game.Start().Turn() == chess.Black {
+		engineIdx = (white + 1) % 2
 	}
 
-	for idx, u := range ifc {
-		u.Setup()
+	for idx, s := range data.search {
+		if s.mode == searchClock {
+			clocks[idx] = clock.New(s.control)
+		}
+	}
+
+	ts.publish(pool, game, white, -1, nil, nil)
+
+	for failure == nil && game.Termination() == chess.NoTermination && moveIdx < maxMoves {
+		limits := ts.searchLimits(data, clocks, engineIdx, white)
+		ts.setPosition(ifc[engineIdx], opening, moves)
+		start := time.Now()
+		timeout := ts.searchTimeout(limits, engineIdx, white)
+		var info *uci.MoveInfo
 
-		if opt := u.GetOptionConfig("hash"); opt != nil {
-			u.SetOption(opt.Response(strconv.Itoa(data.options[idx].hash)))
+		if data.trace {
+			info = ifc[engineIdx].SearchTrace(limits, timeout)
+		} else {
+			info = ifc[engineIdx].SearchTimeout(limits, timeout)
 		}
+		elapsed := time.Since(start)
+
+		if info == nil {
+			kind := testflow.HangFailure
+			msg := "Engine did not respond within " + elapsed.Round(time.Millisecond).String()
+
+			if ifc[engineIdx].Exited() {
+				kind = testflow.CrashFailure
+				msg = "Engine exited during the search"
+			}
 
-		if opt := u.GetOptionConfig("threads"); opt != nil {
-			u.SetOption(opt.Response(strconv.Itoa(data.options[idx].threads)))
+			pool.fail(engineIdx)
+			failure = ts.failure(kind, engineIdx, msg, logs[engineIdx])
+			break
 		}
 
-		u.Start()
-	}
+		history[engineIdx] = append(history[engineIdx], *info)
+		samples[engineIdx] = append(samples[engineIdx], testflow.MoveSample{
+			Depth:    info.Depth,
+			Nps:      info.Nps,
+			Time:     int(elapsed.Milliseconds()),
+			Allotted: ts.allottedTime(limits, engineIdx, white),
+		})
+
+		if err := game.Move(info.Move); err != nil {
+			loser = engineIdx
+			reason = "illegal move"
+			break
+		}
 
-	for !ts.isGameOver(info) && moveIdx < maxMoves {
-		ifc[engineIdx].SetMoves(moves...)
-		info = ifc[engineIdx].GetMove(data.search[engineIdx].value)
 		moves = append(moves, info.Move)
-		history[engineIdx] = append(history[engineIdx], *info)
+		ts.publish(pool, game, white, engineIdx, info, nil)
+
+		if data.search[engineIdx].mode == searchClock && !clocks[engineIdx].Punch(elapsed, conf.GetTimeOverhead()) {
+			loser = engineIdx
+			reason = "time forfeit"
+			break
+		}
+
 		engineIdx = (engineIdx + 1) % 2
 		moveIdx++
 	}
 
-	for _, u := range ifc {
-		u.Quit()
-		u.Close()
-	}
+	result := ts.gameResult(game, white, loser, reason, failure)
+	result.Perf = [2]testflow.PerfStats{testflow.Summarize(samples[0]), testflow.Summarize(samples[1])}
+	ts.publish(pool, game, white, engineIdx, nil, &result)
 
 	return gameMsg{
 		gameCount: 1,
 		moves:     []testflow.GameMoveHistory{history},
 		logs:      []testflow.Log{logs},
+		results:   []testflow.GameResult{result},
+		records:   []gameRecord{{start: opening, moves: moves}},
+		samples:   [][2][]testflow.MoveSample{samples},
 	}
 }
@@ -0,0 +1,397 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
+)
+
+// TournamentFormat is the pairing scheme of a tournament.
+type TournamentFormat string
+
+const (
+	RoundRobin TournamentFormat = "round-robin" // Every engine plays against every other engine.
+	Gauntlet   TournamentFormat = "gauntlet"    // The first engine plays against every other engine.
+)
+
+// TournamentConfig configures a local tournament between several engines.
+type TournamentConfig struct {
+	Engines     []string         // The engines as path to a binary or as "name@version" of an installed engine.
+	Format      TournamentFormat // The pairing scheme of the tournament.
+	Rounds      int              // The number of game pairs each pairing plays.
+	TimeControl string           // The time control in the format "[moves/]base[+increment]".
+	MoveTime    int              // The time per move in ms. Replaces the time control if set.
+	Depth       int              // The depth per move. Replaces the time control if set.
+	Concurrency int              // The number of games played at the same time. Derived from the hardware if zero.
+	Openings    string           // The path to a file with one FEN or EPD per line.
+	Hash        int              // The hash size of all engines in MB.
+	Threads     int              // The number of threads of all engines.
+	Options     []string         // Custom options in the format "engine:name=value" with the engine numbered from 1.
+	PGN         string           // The path of the PGN file the games are written to. Skipped if empty.
+	JSON        string           // The path of the JSON file the crosstable is written to. Skipped if empty.
+}
+
+// crosstable collects the scores of all pairings of a tournament.
+// The score of a pairing is stored from the perspective of the engine with the lower index.
+type crosstable struct {
+	format TournamentFormat
+	names  []string
+	scores map[[2]int]*score
+}
+
+// standing is the JSON representation of the total score of an engine.
+type standing struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+// pairStanding is the JSON representation of the score of a pairing.
+type pairStanding struct {
+	Engines [2]string `json:"engines"`
+	Score   float64   `json:"score"`
+	Games   int       `json:"games"`
+	Wins    int       `json:"wins"`
+	Losses  int       `json:"losses"`
+	Draws   int       `json:"draws"`
+}
+
+// RunTournament plays a tournament between several engines without a connection to the test server.
+// The games of all pairings are scheduled round by round on the same number of concurrent games.
+// Every opening is played twice with swapped colors by each pairing.
+// The result of each game and the final crosstable are printed to stdout.
+func RunTournament(cfg TournamentConfig) error {
+	ts := testService{discard: true}
+	base := data{state: play}
+	played := 0
+
+	if len(cfg.Engines) < 2 {
+		return errors.New("A tournament requires at least two engines")
+	}
+
+	if cfg.Format != RoundRobin && cfg.Format != Gauntlet {
+		return errors.New("Unknown tournament format: " + string(cfg.Format))
+	}
+
+	s, err := parseSearch(cfg.TimeControl, cfg.MoveTime, cfg.Depth)
+
+	if err != nil {
+		return err
+	}
+
+	if cfg.Openings != "" {
+		if base.openings, err = loadOpenings(cfg.Openings); err != nil {
+			return err
+		}
+	}
+
+	custom, err := parseEngineOptions(cfg.Options, len(cfg.Engines))
+
+	if err != nil {
+		return err
+	}
+
+	base.search = [2]search{s, s}
+	base.options = [2]options{
+		{hash: cfg.Hash, threads: cfg.Threads},
+		{hash: cfg.Hash, threads: cfg.Threads},
+	}
+	base.concurrency = cfg.Concurrency
+
+	if base.concurrency < 1 {
+		base.concurrency = ts.getConcurrency(base.options)
+	}
+
+	table := newCrosstable(cfg.Format, cfg.Engines)
+	pairings := make(map[*data][2]int)
+	jobs := make([]pairJob, 0)
+	var pgn *os.File
+	var pgnErr error
+
+	insts := make([]mgmt.EngineInstance, len(cfg.Engines))
+	paths := make([]string, len(cfg.Engines))
+
+	for idx, spec := range cfg.Engines {
+		inst, path, err := resolveEngine(spec)
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkCustom(path, custom[idx]); err != nil {
+			return err
+		}
+
+		insts[idx] = *inst
+		paths[idx] = path
+	}
+
+	for _, p := range table.pairings() {
+		d := base
+
+		for idx, engine := range p {
+			d.engines[idx] = insts[engine]
+			d.binaries[idx] = paths[engine]
+			d.options[idx].custom = custom[engine]
+		}
+
+		pairings[&d] = p
+	}
+
+	for round := 0; round < cfg.Rounds; round++ {
+		for d := range pairings {
+			jobs = append(jobs, pairJob{data: d, pair: round})
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].pair != jobs[j].pair {
+			return jobs[i].pair < jobs[j].pair
+		}
+
+		return table.less(pairings[jobs[i].data], pairings[jobs[j].data])
+	})
+
+	if cfg.PGN != "" {
+		if pgn, err = os.Create(cfg.PGN); err != nil {
+			return err
+		}
+
+		defer pgn.Close()
+	}
+
+	fmt.Printf("Started %s tournament with %d engines and %d concurrent games\n", cfg.Format, len(cfg.Engines), base.concurrency)
+
+	ts.onPair = func(job pairJob, msg gameMsg) {
+		p := pairings[job.data]
+		names := [2]string{cfg.Engines[p[0]], cfg.Engines[p[1]]}
+
+		for idx, res := range msg.results {
+			played++
+			table.add(p, res)
+			fmt.Println(describeResult(played, names, res))
+
+			if pgn == nil || pgnErr != nil {
+				continue
+			}
+
+			text, err := formatPGN("Local "+string(cfg.Format)+" tournament", job.pair+1, names, msg.records[idx], res)
+
+			if err != nil {
+				fmt.Println("Could not convert game " + strconv.Itoa(played) + " to PGN: " + err.Error())
+				continue
+			}
+
+			if _, err := pgn.WriteString(text); err != nil {
+				pgnErr = errors.New("Could not write PGN: " + err.Error())
+				fmt.Println(pgnErr.Error())
+			}
+		}
+	}
+
+	msg := ts.playJobs(jobs, base.concurrency)
+
+	if err, ok := msg.(error); ok {
+		return err
+	}
+
+	if aborted := msg.(gameMsg).aborted; aborted != "" {
+		fmt.Println()
+		fmt.Println(aborted)
+	}
+
+	fmt.Println()
+	fmt.Print(table.String())
+
+	if cfg.JSON != "" {
+		if err := table.writeJSON(cfg.JSON); err != nil {
+			return err
+		}
+	}
+
+	return pgnErr
+}
+
+func newCrosstable(format TournamentFormat, names []string) *crosstable {
+	return &crosstable{
+		format: format,
+		names:  names,
+		scores: make(map[[2]int]*score),
+	}
+}
+
+// pairings returns the pairs of engine indices which play against each other.
+func (c *crosstable) pairings() [][2]int {
+	res := make([][2]int, 0)
+
+	for i := range c.names {
+		for j := i + 1; j < len(c.names); j++ {
+			if c.format == Gauntlet && i > 0 {
+				break
+			}
+
+			res = append(res, [2]int{i, j})
+		}
+	}
+
+	return res
+}
+
+// less orders two pairings by their engine indices.
+func (c *crosstable) less(a [2]int, b [2]int) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+
+	return a[1] < b[1]
+}
+
+// add adds the result of a game between the engines of the pairing.
+func (c *crosstable) add(p [2]int, res testflow.GameResult) {
+	if c.scores[p] == nil {
+		c.scores[p] = &score{}
+	}
+
+	c.scores[p].add(res)
+}
+
+// pair returns the score of engine i against engine j from the perspective of i.
+// If both engines did not play each other, nil is returned.
+func (c *crosstable) pair(i int, j int) *score {
+	if i < j {
+		return c.scores[[2]int{i, j}]
+	}
+
+	if s := c.scores[[2]int{j, i}]; s != nil {
+		return &score{wins: s.losses, losses: s.wins, draws: s.draws}
+	}
+
+	return nil
+}
+
+// total returns the combined score of an engine against all opponents.
+func (c *crosstable) total(i int) score {
+	res := score{}
+
+	for j := range c.names {
+		if s := c.pair(i, j); s != nil && i != j {
+			res.wins += s.wins
+			res.losses += s.losses
+			res.draws += s.draws
+		}
+	}
+
+	return res
+}
+
+// ranking returns the engine indices ordered by their total score.
+func (c *crosstable) ranking() []int {
+	res := make([]int, len(c.names))
+
+	for i := range res {
+		res[i] = i
+	}
+
+	sort.SliceStable(res, func(a, b int) bool {
+		return c.total(res[a]).points() > c.total(res[b]).points()
+	})
+
+	return res
+}
+
+// String returns the crosstable as text with one row per engine ordered by score.
+func (c *crosstable) String() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	header := []string{"#", "Engine", "Score", "Games"}
+
+	for i := range c.names {
+		header = append(header, fmt.Sprint(i+1))
+	}
+
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for rank, i := range c.ranking() {
+		total := c.total(i)
+		row := []string{
+			fmt.Sprint(rank + 1),
+			fmt.Sprintf("(%d) %s", i+1, c.names[i]),
+			fmt.Sprintf("%.1f", total.points()),
+			fmt.Sprint(total.games()),
+		}
+
+		for j := range c.names {
+			if s := c.pair(i, j); s != nil && i != j {
+				row = append(row, fmt.Sprintf("%.1f/%d", s.points(), s.games()))
+			} else {
+				row = append(row, "-")
+			}
+		}
+
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	w.Flush()
+	return sb.String()
+}
+
+// writeJSON writes the standings of all engines and pairings to the file at path.
+func (c *crosstable) writeJSON(path string) error {
+	out := struct {
+		Format  TournamentFormat `json:"format"`
+		Engines []standing       `json:"engines"`
+		Pairs   []pairStanding   `json:"pairs"`
+	}{
+		Format:  c.format,
+		Engines: make([]standing, 0, len(c.names)),
+		Pairs:   make([]pairStanding, 0),
+	}
+
+	for _, i := range c.ranking() {
+		total := c.total(i)
+		out.Engines = append(out.Engines, standing{
+			Name:   c.names[i],
+			Score:  total.points(),
+			Games:  total.games(),
+			Wins:   total.wins,
+			Losses: total.losses,
+			Draws:  total.draws,
+		})
+	}
+
+	for _, p := range c.pairings() {
+		s := c.pair(p[0], p[1])
+
+		if s == nil {
+			continue
+		}
+
+		out.Pairs = append(out.Pairs, pairStanding{
+			Engines: [2]string{c.names[p[0]], c.names[p[1]]},
+			Score:   s.points(),
+			Games:   s.games(),
+			Wins:    s.wins,
+			Losses:  s.losses,
+			Draws:   s.draws,
+		})
+	}
+
+	buf, err := json.MarshalIndent(out, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf, 0644)
+}
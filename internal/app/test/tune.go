@@ -0,0 +1,241 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/spsa"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+)
+
+// TuneConfig configures a local SPSA tuning run of an engine.
+type TuneConfig struct {
+	Engine      string // The engine as path to a binary or as "name@version" of an installed engine.
+	Params      string // The path to the parameter list. Ignored when resuming.
+	Iterations  int    // The number of game pairs to play. Ignored when resuming.
+	Checkpoint  string // The path to the checkpoint, which is written after every round.
+	Resume      bool   // Whether to continue the run saved in the checkpoint.
+	TimeControl string // The time control in the format "[moves/]base[+increment]".
+	MoveTime    int    // The time per move in ms. Replaces the time control if set.
+	Depth       int    // The depth per move. Replaces the time control if set.
+	Concurrency int    // The number of game pairs played at the same time. Derived from the hardware if zero.
+	Openings    string // The path to a file with one FEN or EPD per line.
+	Hash        int    // The hash size in MB.
+	Threads     int    // The number of threads.
+}
+
+// RunTune tunes the parameters of an engine with SPSA by letting the engine
+// play against itself with perturbed parameters.
+// Each round plays one game pair per concurrent game, updates the parameters
+// after each pair and saves a checkpoint, so an interrupted run can be resumed.
+// Pairs with a failed game are skipped. The run is aborted once the share of
+// failed games exceeds the maximum failure rate.
+func RunTune(cfg TuneConfig) error {
+	ts := testService{discard: true}
+	base := &data{state: play}
+	inst, path, err := resolveEngine(cfg.Engine)
+
+	if err != nil {
+		return err
+	}
+
+	base.engines = [2]mgmt.EngineInstance{*inst, *inst}
+	base.binaries = [2]string{path, path}
+	s, err := parseSearch(cfg.TimeControl, cfg.MoveTime, cfg.Depth)
+
+	if err != nil {
+		return err
+	}
+
+	if cfg.Openings != "" {
+		if base.openings, err = loadOpenings(cfg.Openings); err != nil {
+			return err
+		}
+	}
+
+	tuner, err := loadTuner(cfg)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkParams(base.enginePath(0), tuner.Params); err != nil {
+		return err
+	}
+
+	base.search = [2]search{s, s}
+	base.options = [2]options{
+		{hash: cfg.Hash, threads: cfg.Threads},
+		{hash: cfg.Hash, threads: cfg.Threads},
+	}
+	base.concurrency = cfg.Concurrency
+
+	if base.concurrency < 1 {
+		base.concurrency = ts.getConcurrency(base.options)
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	played := 0
+	failed := 0
+
+	fmt.Printf("Tuning %d parameters of %s from iteration %d of %d with %d concurrent games\n", len(tuner.Params), cfg.Engine, tuner.Iteration, tuner.Iterations, base.concurrency)
+
+	for !tuner.Done() {
+		count := tuner.Iterations - tuner.Iteration
+
+		if count > base.concurrency {
+			count = base.concurrency
+		}
+
+		jobs := make([]pairJob, count)
+		perturbations := make(map[*data]spsa.Perturbation)
+
+		for i := range jobs {
+			p := tuner.Perturb(tuner.Iteration+i, rng)
+			d := *base
+			d.options = [2]options{
+				{hash: cfg.Hash, threads: cfg.Threads, custom: paramOptions(tuner.Params, p.Plus)},
+				{hash: cfg.Hash, threads: cfg.Threads, custom: paramOptions(tuner.Params, p.Minus)},
+			}
+			jobs[i] = pairJob{data: &d, pair: p.Iteration}
+			perturbations[&d] = p
+		}
+
+		ts.onPair = func(job pairJob, msg gameMsg) {
+			d := job.data
+			result := 0.0
+			skipped := ""
+
+			for _, res := range msg.results {
+				played++
+
+				if res.Failure != nil {
+					failed++
+					skipped = res.Failure.Message
+					continue
+				}
+
+				result += 2*engineScore(res) - 1
+			}
+
+			if skipped != "" {
+				fmt.Println("Skipped game pair: " + skipped)
+				return
+			}
+
+			tuner.Update(perturbations[d], result)
+			fmt.Printf("Iteration %d/%d (%+g): %s\n", tuner.Iteration, tuner.Iterations, result, describeParams(tuner.Params, tuner.Values()))
+		}
+
+		msg := ts.playJobs(jobs, base.concurrency)
+
+		if err, ok := msg.(error); ok {
+			return err
+		}
+
+		if err := tuner.Save(cfg.Checkpoint); err != nil {
+			return err
+		}
+
+		if aborted := msg.(gameMsg).aborted; aborted != "" {
+			return errors.New(aborted)
+		}
+
+		if played >= minFailureSample && float64(failed)/float64(played) > conf.GetMaxFailureRate() {
+			return errors.New("Aborted tuning: " + strconv.Itoa(failed) + " of " + strconv.Itoa(played) + " games failed")
+		}
+	}
+
+	fmt.Printf("Finished tuning: %s\n", describeParams(tuner.Params, tuner.Values()))
+	return nil
+}
+
+// loadTuner returns the tuner saved in the checkpoint when resuming, or a new tuner for the parameter list.
+func loadTuner(cfg TuneConfig) (*spsa.Tuner, error) {
+	if cfg.Resume {
+		return spsa.Load(cfg.Checkpoint)
+	}
+
+	if cfg.Iterations < 1 {
+		return nil, errors.New("At least one iteration is required")
+	}
+
+	file, err := os.Open(cfg.Params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	params, err := spsa.ParseParams(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return spsa.New(params, cfg.Iterations), nil
+}
+
+// checkParams launches the engine and verifies that each parameter is a spin
+// option of the engine and its range lies within the range of the option.
+func checkParams(path string, params []spsa.Param) error {
+	ifc, err := uci.NewFromExe(path, nil, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer ifc.Close()
+	defer ifc.Quit()
+
+	if !ifc.SetupTimeout(readyTimeout) {
+		return errors.New("Engine did not answer uci: " + path)
+	}
+
+	for _, p := range params {
+		opt := ifc.GetOptionConfig(p.Name)
+
+		if opt == nil {
+			return errors.New("Unsupported option: " + p.Name)
+		}
+
+		if opt.Type != uci.Spin {
+			return errors.New("Option is not a spin option: " + p.Name)
+		}
+
+		if p.Min < opt.Min || p.Max > opt.Max {
+			return errors.New("Range of parameter " + p.Name + " exceeds the range of the option: " + strconv.Itoa(opt.Min) + " to " + strconv.Itoa(opt.Max))
+		}
+	}
+
+	return nil
+}
+
+// paramOptions returns the custom engine options which set the parameters to the given values.
+func paramOptions(params []spsa.Param, values []int) map[string]string {
+	custom := make(map[string]string, len(params))
+
+	for idx, p := range params {
+		custom[p.Name] = strconv.Itoa(values[idx])
+	}
+
+	return custom
+}
+
+// describeParams returns the parameters with their values in the format "name=value".
+func describeParams(params []spsa.Param, values []int) string {
+	parts := make([]string, len(params))
+
+	for idx, p := range params {
+		parts[idx] = p.Name + "=" + strconv.Itoa(values[idx])
+	}
+
+	return strings.Join(parts, " ")
+}
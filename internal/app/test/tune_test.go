@@ -0,0 +1,58 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// crashingEngine answers the uci handshake and exits as soon as it has to search.
+const crashingEngine = `#!/bin/sh
+echo "Crash"
+while read line; do
+	case "$line" in
+		uci) echo "id name Crash"; echo "option name Margin type spin default 50 min 0 max 100"; echo "uciok" ;;
+		isready) echo "readyok" ;;
+		go*) exit 1 ;;
+		quit) exit 0 ;;
+	esac
+done
+`
+
+func TestTuneCrashingEngine(t *testing.T) {
+	dir := t.TempDir()
+	engine := filepath.Join(dir, "crash.sh")
+	params := filepath.Join(dir, "params.txt")
+
+	if err := os.WriteFile(engine, []byte(crashingEngine), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(params, []byte("Margin 50 0 100 10\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- RunTune(TuneConfig{
+			Engine:      engine,
+			Params:      params,
+			Iterations:  1000,
+			Checkpoint:  filepath.Join(dir, "checkpoint.json"),
+			MoveTime:    10,
+			Concurrency: 1,
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.HasPrefix(err.Error(), "Aborted tuning") {
+			t.Errorf("Expected tuning with a crashing engine to be aborted, got %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("Expected tuning with a crashing engine to be aborted")
+	}
+}
@@ -1,10 +1,15 @@
 package test
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com/testflow"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/conf"
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/stats"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/tui"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -38,9 +43,78 @@ func (m model) View() string {
 		Padding(1, 2)
 
 	return wrapper.Render(m.createStatsPanel().build()) +
+		"\n" +
+		wrapper.Render(m.createResultsPanel().build()) +
 		"\n" +
 		wrapper.Render(m.createEnginesPanel().build()) +
-		"\n"
+		"\n" +
+		wrapper.Render(m.createPerformancePanel().build()) +
+		"\n" +
+		m.createGamesView(wrapper)
+}
+
+// createGamesView returns the panel of the selected game in progress or an empty string if no game is played.
+func (m model) createGamesView(wrapper lipgloss.Style) string {
+	if m.data.state != play && m.data.state != drain {
+		return ""
+	}
+
+	if p := m.createGamesPanel(); p != nil {
+		return wrapper.Render(p.build()) + "\n"
+	}
+
+	return ""
+}
+
+func (m model) createGamesPanel() *panel {
+	game, idx := m.data.selectedGame()
+
+	if game == nil {
+		return nil
+	}
+
+	black := (game.white + 1) % 2
+	names := [2]string{}
+	stateMsg := "Move " + strconv.Itoa(game.position.Fullmove()) + ", " + game.position.Turn().String() + " to move"
+
+	for i, engine := range m.data.engines {
+		names[i] = engine.Engine + " " + engine.Version.String(mgmt.DotVersionStyle)
+	}
+
+	if game.result != nil {
+		stateMsg = "Finished " + string(game.result.Outcome) + " (" + game.result.Reason + ")"
+	}
+
+	return &panel{
+		title: fmt.Sprintf("Game %d of %d  (← →)", idx+1, len(m.data.live)),
+		width: 60,
+		rows: []panelRow{
+			{
+				label: "White / Black",
+				value: []string{names[game.white], names[black]},
+			},
+			{
+				label: "State",
+				value: []string{stateMsg},
+			},
+			{
+				label: "Score",
+				value: []string{formatScore(game.scores[game.white]), formatScore(game.scores[black])},
+			},
+			{
+				label: "Depth",
+				value: []string{formatDepth(game.depths[game.white]), formatDepth(game.depths[black])},
+			},
+			{
+				label: "Eval",
+				value: []string{sparkline(game.evals, sparkWidth)},
+			},
+			{
+				label: "Board",
+				value: []string{game.position.Board(true)},
+			},
+		},
+	}
 }
 
 func (m model) createEnginesPanel() *panel {
@@ -74,7 +148,7 @@ func (m model) createEnginesPanel() *panel {
 		"(none)",
 	}
 
-	if m.data.state == play {
+	if m.data.state == play || m.data.state == drain {
 		for idx, engine := range m.data.engines {
 			names[idx] = engine.Engine
 			versions[idx] = engine.Version.String(mgmt.DotVersionStyle)
@@ -88,6 +162,9 @@ func (m model) createEnginesPanel() *panel {
 			case searchTime:
 				modes[idx] = "Time"
 				values[idx] = (time.Duration(m.data.search[idx].value) * time.Millisecond).String()
+			case searchClock:
+				modes[idx] = "Clock"
+				values[idx] = m.data.search[idx].control.String()
 			}
 		}
 	}
@@ -128,7 +205,26 @@ func (m model) createStatsPanel() *panel {
 	stateMsg := ""
 	playedMsg := strconv.Itoa(m.data.played)
 	uptimeMsg := m.uptime.View()
+	res := conf.GetResourceConfig()
 	concurrencyMsg := strconv.Itoa(m.data.concurrency)
+	coresMsg := strconv.Itoa(m.data.cores)
+	memoryMsg := strconv.Itoa(m.data.memory) + " MB"
+
+	if res.Concurrency > 0 {
+		concurrencyMsg += " (fixed)"
+	}
+
+	if res.MaxCores > 0 {
+		coresMsg += " (max " + strconv.Itoa(res.MaxCores) + ")"
+	}
+
+	if res.ReserveCores > 0 {
+		coresMsg += " (" + strconv.Itoa(res.ReserveCores) + " reserved)"
+	}
+
+	if res.MaxMemory > 0 {
+		memoryMsg += " (max " + strconv.Itoa(res.MaxMemory) + " MB)"
+	}
 
 	switch m.data.state {
 	case connect:
@@ -137,6 +233,12 @@ func (m model) createStatsPanel() *panel {
 		stateMsg = "Playing Game"
 	case wait:
 		stateMsg = "Waiting for game to start..."
+
+		if m.data.aborted != "" {
+			stateMsg += " (last batch: " + m.data.aborted + ")"
+		}
+	case drain:
+		stateMsg = "Draining, " + strconv.Itoa(m.service.remaining()) + " games remaining"
 	}
 
 	return &panel{
@@ -159,19 +261,111 @@ func (m model) createStatsPanel() *panel {
 				label: "Concurrent Games",
 				value: []string{concurrencyMsg},
 			},
+			{
+				label: "Usable Cores",
+				value: []string{coresMsg},
+			},
+			{
+				label: "Usable Memory",
+				value: []string{memoryMsg},
+			},
+		},
+	}
+}
+
+func (m model) createResultsPanel() *panel {
+	g := m.data.games
+	p := m.data.pairs
+	sprt := m.data.sprt()
+	lower, upper := sprt.Bounds()
+	llr := sprt.LLR(p)
+	elo := m.data.elo()
+	decisionMsg := "Running"
+	decisionStyle := lipgloss.NewStyle().Bold(true)
+
+	switch sprt.Decide(llr) {
+	case stats.AcceptH0:
+		decisionMsg = "H0 accepted, test would stop"
+		decisionStyle = decisionStyle.Foreground(lipgloss.Color("9"))
+	case stats.AcceptH1:
+		decisionMsg = "H1 accepted, test would stop"
+		decisionStyle = decisionStyle.Foreground(lipgloss.Color("10"))
+	}
+
+	return &panel{
+		title: "Results",
+		width: 60,
+		rows: []panelRow{
+			{
+				label: "W / D / L",
+				value: []string{
+					fmt.Sprintf("%d / %d / %d", g.Wins, g.Draws, g.Losses),
+					fmt.Sprintf("%d / %d / %d", g.Losses, g.Draws, g.Wins),
+				},
+			},
+			{
+				label: "Pairs",
+				value: []string{fmt.Sprintf("%d / %d / %d / %d / %d", p[0], p[1], p[2], p[3], p[4])},
+			},
+			{
+				label: "Elo",
+				value: []string{fmt.Sprintf("%+.1f ± %.1f", elo.Elo, (elo.Upper-elo.Lower)/2)},
+			},
+			{
+				label: "LOS",
+				value: []string{fmt.Sprintf("%.1f %%", g.LOS()*100)},
+			},
+			{
+				label: "LLR",
+				value: []string{fmt.Sprintf("%.2f (%.2f, %.2f) [%g, %g]", llr, lower, upper, sprt.Elo0, sprt.Elo1)},
+			},
+			{
+				label: "SPRT",
+				value: []string{decisionStyle.Render(decisionMsg)},
+			},
+		},
+	}
+}
+
+func (m model) createPerformancePanel() *panel {
+	perf := m.data.perf
+	row := func(label string, format func(testflow.PerfStats) string) panelRow {
+		return panelRow{
+			label: label,
+			value: []string{format(perf[0]), format(perf[1])},
+		}
+	}
+
+	return &panel{
+		title: "Performance",
+		width: 60,
+		rows: []panelRow{
+			row("Moves", func(p testflow.PerfStats) string {
+				return strconv.Itoa(p.Moves)
+			}),
+			row("Depth", func(p testflow.PerfStats) string {
+				return fmt.Sprintf("%.1f / %.1f", p.MeanDepth, p.MedianDepth)
+			}),
+			row("NPS", func(p testflow.PerfStats) string {
+				return fmt.Sprintf("%s / %s", tui.FormatCount(p.MeanNps), tui.FormatCount(p.MedianNps))
+			}),
+			row("Time", func(p testflow.PerfStats) string {
+				return fmt.Sprintf("%.0f / %.0f ms", p.MeanTime, p.MedianTime)
+			}),
+			row("Time Usage", func(p testflow.PerfStats) string {
+				return fmt.Sprintf("%.1f %%", p.TimeUsage*100)
+			}),
+			row("Overshoots", func(p testflow.PerfStats) string {
+				return fmt.Sprintf("%d (%.1f %%)", p.Overshoots, p.OvershootRate*100)
+			}),
 		},
 	}
 }
 
 func (p panel) build() string {
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("241")).
-		Padding(0, 1)
+	boxStyle := tui.Box()
 
-	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#B949FF")).
-		Bold(true).
+	titleStyle := tui.Title().
 		Align(lipgloss.Center).
 		PaddingBottom(1)
 
@@ -0,0 +1,406 @@
+// Package chess implements the rules of chess which are required to referee
+// games between engines. It parses and formats FEN strings, generates legal
+// moves and detects the end of a game.
+package chess
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Color is the color of a piece or player.
+type Color int8
+
+const (
+	White Color = iota // The white pieces
+	Black              // The black pieces
+)
+
+// PieceType is the kind of a piece regardless of its color.
+type PieceType int8
+
+const (
+	NoPieceType PieceType = iota // An empty square
+	Pawn                         // A pawn
+	Knight                       // A knight
+	Bishop                       // A bishop
+	Rook                         // A rook
+	Queen                        // A queen
+	King                         // A king
+)
+
+// Piece is a piece of a given type and color.
+// The zero value represents an empty square.
+type Piece struct {
+	Type  PieceType
+	Color Color
+}
+
+// Square is a square on the board, starting with a1 = 0 and ending with h8 = 63.
+type Square int8
+
+// NoSquare is used when a square is not set, e.g. if en passant is not possible.
+const NoSquare Square = -1
+
+// StartFEN is the FEN of the standard starting position.
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+const (
+	whiteKingside uint8 = 1 << iota
+	whiteQueenside
+	blackKingside
+	blackQueenside
+)
+
+// Position is a position on the board including all information of a FEN string.
+// Positions are immutable. Playing a move returns a new position.
+type Position struct {
+	board    [64]Piece
+	turn     Color
+	castling uint8
+	ep       Square
+	halfmove int
+	fullmove int
+}
+
+var pieceSymbols = map[PieceType]byte{
+	Pawn:   'p',
+	Knight: 'n',
+	Bishop: 'b',
+	Rook:   'r',
+	Queen:  'q',
+	King:   'k',
+}
+
+var figurines = map[PieceType][2]string{
+	Pawn:   {"♙", "♟"},
+	Knight: {"♘", "♞"},
+	Bishop: {"♗", "♝"},
+	Rook:   {"♖", "♜"},
+	Queen:  {"♕", "♛"},
+	King:   {"♔", "♚"},
+}
+
+// Other returns the opposite color.
+func (c Color) Other() Color {
+	return 1 - c
+}
+
+// String returns "white" or "black".
+func (c Color) String() string {
+	if c == White {
+		return "white"
+	}
+
+	return "black"
+}
+
+// Symbol returns the FEN symbol of the piece.
+// Uppercase letters are used for white pieces and '.' for empty squares.
+func (p Piece) Symbol() byte {
+	sym, ok := pieceSymbols[p.Type]
+
+	if !ok {
+		return '.'
+	}
+
+	if p.Color == White {
+		return sym - 'a' + 'A'
+	}
+
+	return sym
+}
+
+// Figurine returns the Unicode chess symbol of the piece or "·" for empty squares.
+func (p Piece) Figurine() string {
+	sym, ok := figurines[p.Type]
+
+	if !ok {
+		return "·"
+	}
+
+	return sym[p.Color]
+}
+
+// File returns the file of the square starting with 0 for the a-file.
+func (s Square) File() int {
+	return int(s) % 8
+}
+
+// Rank returns the rank of the square starting with 0 for the first rank.
+func (s Square) Rank() int {
+	return int(s) / 8
+}
+
+// String returns the algebraic notation of the square, e.g. "e4".
+func (s Square) String() string {
+	if s < 0 || s > 63 {
+		return "-"
+	}
+
+	return string([]byte{byte('a' + s.File()), byte('1' + s.Rank())})
+}
+
+// ParseSquare parses a square in algebraic notation, e.g. "e4".
+func ParseSquare(s string) (Square, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return NoSquare, errors.New("invalid square: " + s)
+	}
+
+	return square(int(s[0]-'a'), int(s[1]-'1')), nil
+}
+
+// ParseFEN parses a position from a FEN string.
+// The halfmove clock and fullmove number may be omitted, as done in EPD strings.
+func ParseFEN(fen string) (*Position, error) {
+	fields := strings.Fields(fen)
+	pos := &Position{ep: NoSquare, fullmove: 1}
+
+	if len(fields) < 4 {
+		return nil, errors.New("invalid FEN, expected at least four fields: " + fen)
+	}
+
+	ranks := strings.Split(fields[0], "/")
+
+	if len(ranks) != 8 {
+		return nil, errors.New("invalid FEN, expected eight ranks: " + fen)
+	}
+
+	for idx, row := range ranks {
+		rank := 7 - idx
+		file := 0
+
+		for _, c := range []byte(row) {
+			if c >= '1' && c <= '8' {
+				file += int(c - '0')
+				continue
+			}
+
+			piece, ok := parsePiece(c)
+
+			if !ok || file > 7 {
+				return nil, errors.New("invalid FEN, bad piece placement: " + fen)
+			}
+
+			pos.board[square(file, rank)] = piece
+			file++
+		}
+
+		if file != 8 {
+			return nil, errors.New("invalid FEN, bad piece placement: " + fen)
+		}
+	}
+
+	switch fields[1] {
+	case "w":
+		pos.turn = White
+	case "b":
+		pos.turn = Black
+	default:
+		return nil, errors.New("invalid FEN, bad side to move: " + fen)
+	}
+
+	if fields[2] != "-" {
+		for _, c := range fields[2] {
+			switch c {
+			case 'K':
+				pos.castling |= whiteKingside
+			case 'Q':
+				pos.castling |= whiteQueenside
+			case 'k':
+				pos.castling |= blackKingside
+			case 'q':
+				pos.castling |= blackQueenside
+			default:
+				return nil, errors.New("invalid FEN, bad castling rights: " + fen)
+			}
+		}
+	}
+
+	if fields[3] != "-" {
+		sq, err := ParseSquare(fields[3])
+
+		if err != nil || (pos.turn == White && sq.Rank() != 5) || (pos.turn == Black && sq.Rank() != 2) {
+			return nil, errors.New("invalid FEN, bad en passant square: " + fen)
+		}
+
+		pos.ep = sq
+
+		if !pos.canCaptureEnPassant() {
+			pos.ep = NoSquare
+		}
+	}
+
+	if len(fields) > 4 {
+		if n, err := strconv.Atoi(fields[4]); err == nil && n >= 0 {
+			pos.halfmove = n
+		} else {
+			return nil, errors.New("invalid FEN, bad halfmove clock: " + fen)
+		}
+	}
+
+	if len(fields) > 5 {
+		if n, err := strconv.Atoi(fields[5]); err == nil && n > 0 {
+			pos.fullmove = n
+		} else {
+			return nil, errors.New("invalid FEN, bad fullmove number: " + fen)
+		}
+	}
+
+	if pos.count(Piece{King, White}) != 1 || pos.count(Piece{King, Black}) != 1 {
+		return nil, errors.New("invalid FEN, each side needs exactly one king: " + fen)
+	}
+
+	if pos.isAttacked(pos.king(pos.turn.Other()), pos.turn) {
+		return nil, errors.New("invalid FEN, the side not to move is in check: " + fen)
+	}
+
+	return pos, nil
+}
+
+// FEN returns the FEN string of the position.
+func (p *Position) FEN() string {
+	return p.key() + " " + strconv.Itoa(p.halfmove) + " " + strconv.Itoa(p.fullmove)
+}
+
+// Turn returns the color of the side to move.
+func (p *Position) Turn() Color {
+	return p.turn
+}
+
+// PieceAt returns the piece on the given square.
+func (p *Position) PieceAt(sq Square) Piece {
+	return p.board[sq]
+}
+
+// Fullmove returns the number of the current full move, starting at 1.
+func (p *Position) Fullmove() int {
+	return p.fullmove
+}
+
+// Board returns a diagram of the position with white at the bottom.
+// Each rank is printed on its own line, followed by a line with the file names.
+// If unicode is false, the FEN symbols of the pieces are used instead of figurines.
+func (p *Position) Board(unicode bool) string {
+	var sb strings.Builder
+
+	for rank := 7; rank >= 0; rank-- {
+		sb.WriteString(strconv.Itoa(rank + 1))
+
+		for file := 0; file < 8; file++ {
+			piece := p.board[square(file, rank)]
+			sb.WriteByte(' ')
+
+			if unicode {
+				sb.WriteString(piece.Figurine())
+			} else {
+				sb.WriteByte(piece.Symbol())
+			}
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	sb.WriteString("  a b c d e f g h")
+	return sb.String()
+}
+
+// key returns the FEN string without the move counters.
+// Two positions with the same key are considered equal for repetitions.
+func (p *Position) key() string {
+	var sb strings.Builder
+
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+
+		for file := 0; file < 8; file++ {
+			piece := p.board[square(file, rank)]
+
+			if piece.Type == NoPieceType {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+
+			sb.WriteByte(piece.Symbol())
+		}
+
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+
+		if rank > 0 {
+			sb.WriteByte('/')
+		}
+	}
+
+	if p.turn == White {
+		sb.WriteString(" w ")
+	} else {
+		sb.WriteString(" b ")
+	}
+
+	if p.castling == 0 {
+		sb.WriteByte('-')
+	}
+
+	for idx, c := range "KQkq" {
+		if p.castling&(1<<idx) != 0 {
+			sb.WriteRune(c)
+		}
+	}
+
+	sb.WriteString(" " + p.ep.String())
+
+	return sb.String()
+}
+
+func (p *Position) count(piece Piece) int {
+	n := 0
+
+	for _, pc := range p.board {
+		if pc == piece {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (p *Position) king(c Color) Square {
+	for sq, pc := range p.board {
+		if pc.Type == King && pc.Color == c {
+			return Square(sq)
+		}
+	}
+
+	return NoSquare
+}
+
+func parsePiece(c byte) (Piece, bool) {
+	color := White
+	lower := c
+
+	if c >= 'a' && c <= 'z' {
+		color = Black
+	} else {
+		lower = c - 'A' + 'a'
+	}
+
+	for t, sym := range pieceSymbols {
+		if sym == lower {
+			return Piece{t, color}, true
+		}
+	}
+
+	return Piece{}, false
+}
+
+func square(file int, rank int) Square {
+	return Square(rank*8 + file)
+}
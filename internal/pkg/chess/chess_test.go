@@ -0,0 +1,233 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+type perft_io struct {
+	fen   string
+	nodes []int
+}
+
+type termination_io struct {
+	fen   string
+	moves []string
+	out   Termination
+}
+
+var perfts = []perft_io{
+	{StartFEN, []int{20, 400, 8902}},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039, 97862}},
+	{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812}},
+	{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467}},
+	{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486, 62379}},
+}
+
+var terminations = []termination_io{
+	{StartFEN, []string{}, NoTermination},
+	{StartFEN, []string{"f2f3", "e7e5", "g2g4", "d8h4"}, Checkmate},
+	{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", []string{}, Stalemate},
+	{StartFEN, []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1", "f6g8"}, Repetition},
+	{"4k3/8/8/8/8/8/8/R3K3 w - - 99 80", []string{"a1a2"}, FiftyMoves},
+	{"4k3/8/8/8/8/8/8/4KB2 w - - 0 1", []string{}, InsufficientMaterial},
+	{"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", []string{}, InsufficientMaterial},
+	{"4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", []string{}, NoTermination},
+}
+
+type san_io struct {
+	fen  string
+	move string
+	out  string
+}
+
+var sans = []san_io{
+	{StartFEN, "g1f3", "Nf3"},
+	{StartFEN, "e2e4", "e4"},
+	{"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", "e4d5", "exd5"},
+	{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+	{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O"},
+	{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "a1d1", "Rad1"},
+	{"4k3/8/8/8/8/8/R7/R3K3 w - - 0 1", "a1b1", "Rb1"},
+	{"4k3/8/8/8/8/8/R7/R3K3 w - - 0 1", "a2a4", "Ra4"},
+	{"4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "a1a3", "R1a3"},
+	{"7k/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q", "a8=Q+"},
+	{"8/8/8/3pP3/8/8/8/4K2k w - d6 0 1", "e5d6", "exd6"},
+	{"rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2", "d8h4", "Qh4#"},
+	{"1k6/8/8/8/8/2N3N1/8/2N1K3 w - - 0 1", "c3e2", "Nc3e2"},
+}
+
+var fens = []string{
+	StartFEN,
+	"rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/8/8/3pP3/8/8/8/4K2k w - d6 0 1",
+}
+
+func perft(p *Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	nodes := 0
+
+	for _, m := range p.LegalMoves() {
+		nodes += perft(p.Play(m), depth-1)
+	}
+
+	return nodes
+}
+
+func TestPerft(t *testing.T) {
+	for _, io := range perfts {
+		pos, err := ParseFEN(io.fen)
+
+		if err != nil {
+			t.Fatalf("Expected %s to be valid, got %v", io.fen, err)
+		}
+
+		for idx, expected := range io.nodes {
+			if n := perft(pos, idx+1); n != expected {
+				t.Errorf("Expected %d nodes at depth %d for %s, got %d", expected, idx+1, io.fen, n)
+			}
+		}
+	}
+}
+
+func TestFEN(t *testing.T) {
+	for _, fen := range fens {
+		pos, err := ParseFEN(fen)
+
+		if err != nil {
+			t.Errorf("Expected %s to be valid, got %v", fen, err)
+			continue
+		}
+
+		if pos.FEN() != fen {
+			t.Errorf("Expected %s, got %s", fen, pos.FEN())
+		}
+	}
+}
+
+func TestTermination(t *testing.T) {
+	for _, io := range terminations {
+		game, err := NewGame(io.fen)
+
+		if err != nil {
+			t.Fatalf("Expected %s to be valid, got %v", io.fen, err)
+		}
+
+		for _, m := range io.moves {
+			if err := game.Move(m); err != nil {
+				t.Fatalf("Expected %s to be legal, got %v", m, err)
+			}
+		}
+
+		if term := game.Termination(); term != io.out {
+			t.Errorf("Expected %q for %s, got %q", io.out, io.fen, term)
+		}
+	}
+}
+
+func TestIllegalMove(t *testing.T) {
+	game, _ := NewGame(StartFEN)
+
+	for _, m := range []string{"e2e5", "e1g1", "(none)", "e7e5", "a2a1q"} {
+		if err := game.Move(m); err == nil {
+			t.Errorf("Expected %s to be illegal", m)
+		}
+	}
+}
+
+func TestSAN(t *testing.T) {
+	for _, io := range sans {
+		pos, _ := ParseFEN(io.fen)
+		move, err := pos.ParseMove(io.move)
+
+		if err != nil {
+			t.Errorf("Expected %s to be legal in %s, got %v", io.move, io.fen, err)
+			continue
+		}
+
+		if san := pos.SAN(move); san != io.out {
+			t.Errorf("Expected %s, got %s", io.out, san)
+		}
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	for _, io := range sans {
+		pos, _ := ParseFEN(io.fen)
+
+		if move, err := pos.ParseSAN(io.out); err != nil || move.String() != io.move {
+			t.Errorf("Expected %s to be parsed as %s, got %s (%v)", io.out, io.move, move, err)
+		}
+	}
+
+	lenient := map[string]string{"0-0": "e1g1", "O-O+": "e1g1", "Kf1!?": "e1f1", "Rb1": "a1b1"}
+	pos, _ := ParseFEN("4k3/8/8/8/8/8/8/R3K2R w K - 0 1")
+
+	for san, uci := range lenient {
+		if move, err := pos.ParseSAN(san); err != nil || move.String() != uci {
+			t.Errorf("Expected %s to be parsed as %s, got %s (%v)", san, uci, move, err)
+		}
+	}
+
+	promo, _ := ParseFEN("7k/P7/8/8/8/8/8/4K3 w - - 0 1")
+
+	if move, err := promo.ParseSAN("a8Q"); err != nil || move.String() != "a7a8q" {
+		t.Errorf("Expected a8Q to be parsed as a7a8q, got %s (%v)", move, err)
+	}
+
+	for _, san := range []string{"", "e5", "Nf6", "O-O-O", "Kd1e1"} {
+		if _, err := pos.ParseSAN(san); err == nil {
+			t.Errorf("Expected %s to be illegal", san)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	game, err := Replay(StartFEN, "e2e4", "e7e5", "g1f3")
+
+	if err != nil {
+		t.Fatalf("Expected moves to be legal, got %v", err)
+	}
+
+	if h := strings.Join(game.History(), " | "); h != "1. e4 e5 | 2. Nf3" {
+		t.Errorf("Expected 1. e4 e5 | 2. Nf3, got %s", h)
+	}
+
+	game, _ = Replay("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1", "e7e5", "g1f3")
+
+	if h := strings.Join(game.History(), " | "); h != "1... e5 | 2. Nf3" {
+		t.Errorf("Expected 1... e5 | 2. Nf3, got %s", h)
+	}
+
+	if _, err := Replay(StartFEN, "e2e4", "e2e4"); err == nil {
+		t.Errorf("Expected replay of an illegal move to fail")
+	}
+}
+
+func TestBoard(t *testing.T) {
+	pos, _ := ParseFEN("8/8/8/3pP3/8/8/8/4K2k w - d6 0 1")
+	ascii := "8 . . . . . . . .\n" +
+		"7 . . . . . . . .\n" +
+		"6 . . . . . . . .\n" +
+		"5 . . . p P . . .\n" +
+		"4 . . . . . . . .\n" +
+		"3 . . . . . . . .\n" +
+		"2 . . . . . . . .\n" +
+		"1 . . . . K . . k\n" +
+		"  a b c d e f g h"
+
+	if board := pos.Board(false); board != ascii {
+		t.Errorf("Expected\n%s\ngot\n%s", ascii, board)
+	}
+
+	start, _ := ParseFEN(StartFEN)
+	first := "8 ♜ ♞ ♝ ♛ ♚ ♝ ♞ ♜"
+
+	if board := start.Board(true); !strings.HasPrefix(board, first+"\n") {
+		t.Errorf("Expected board to start with %s, got\n%s", first, board)
+	}
+}
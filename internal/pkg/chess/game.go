@@ -0,0 +1,175 @@
+package chess
+
+import "strconv"
+
+// Termination is the reason why a game ended.
+type Termination string
+
+const (
+	NoTermination        Termination = ""                      // The game is still in progress.
+	Checkmate            Termination = "checkmate"             // The side to move is checkmated.
+	Stalemate            Termination = "stalemate"             // The side to move has no legal moves but is not in check.
+	Repetition           Termination = "threefold repetition"  // The same position occurred three times.
+	FiftyMoves           Termination = "fifty-move rule"       // No capture or pawn move was played in the last fifty moves.
+	InsufficientMaterial Termination = "insufficient material" // Neither side is able to checkmate.
+)
+
+// Game is a sequence of moves played from a starting position.
+type Game struct {
+	positions []*Position
+	moves     []Move
+}
+
+// NewGame starts a new game from the position described by the FEN string.
+func NewGame(fen string) (*Game, error) {
+	pos, err := ParseFEN(fen)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Game{
+		positions: []*Position{pos},
+		moves:     make([]Move, 0),
+	}, nil
+}
+
+// Replay starts a new game from the position described by the FEN string and
+// plays the moves given in UCI notation.
+// An error is returned if the FEN is invalid or a move is not legal.
+func Replay(fen string, moves ...string) (*Game, error) {
+	game, err := NewGame(fen)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range moves {
+		if err := game.Move(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return game, nil
+}
+
+// Start returns the starting position of the game.
+func (g *Game) Start() *Position {
+	return g.positions[0]
+}
+
+// Position returns the current position of the game.
+func (g *Game) Position() *Position {
+	return g.positions[len(g.positions)-1]
+}
+
+// Moves returns the moves played so far.
+func (g *Game) Moves() []Move {
+	return g.moves
+}
+
+// History returns the moves played so far in standard algebraic notation with
+// one entry per full move, e.g. "1. e4 e5". If black moved first, the first
+// entry starts with "1...".
+func (g *Game) History() []string {
+	lines := make([]string, 0, len(g.moves)/2+1)
+
+	for idx, move := range g.moves {
+		pos := g.positions[idx]
+		san := pos.SAN(move)
+
+		switch {
+		case pos.Turn() == White:
+			lines = append(lines, strconv.Itoa(pos.Fullmove())+". "+san)
+		case idx == 0:
+			lines = append(lines, strconv.Itoa(pos.Fullmove())+"... "+san)
+		default:
+			lines[len(lines)-1] += " " + san
+		}
+	}
+
+	return lines
+}
+
+// Move plays the move given in UCI notation.
+// An error is returned if the move is not legal in the current position.
+func (g *Game) Move(uci string) error {
+	move, err := g.Position().ParseMove(uci)
+
+	if err != nil {
+		return err
+	}
+
+	g.moves = append(g.moves, move)
+	g.positions = append(g.positions, g.Position().Play(move))
+
+	return nil
+}
+
+// Termination returns the reason why the game ended or NoTermination if the
+// game is still in progress. If the game ended by checkmate, the side to move
+// of the current position lost the game. All other terminations are draws.
+func (g *Game) Termination() Termination {
+	pos := g.Position()
+
+	if len(pos.LegalMoves()) == 0 {
+		if pos.InCheck() {
+			return Checkmate
+		}
+
+		return Stalemate
+	}
+
+	if pos.halfmove >= 100 {
+		return FiftyMoves
+	}
+
+	if pos.insufficientMaterial() {
+		return InsufficientMaterial
+	}
+
+	key := pos.key()
+	count := 0
+
+	for _, p := range g.positions {
+		if p.key() == key {
+			count++
+		}
+	}
+
+	if count >= 3 {
+		return Repetition
+	}
+
+	return NoTermination
+}
+
+// insufficientMaterial returns whether neither side has enough material to
+// checkmate. This is the case for a bare king against a king with at most a
+// single minor piece or for kings with bishops on squares of the same color.
+func (p *Position) insufficientMaterial() bool {
+	minors := 0
+	bishopColors := [2]bool{}
+
+	for idx, piece := range p.board {
+		sq := Square(idx)
+
+		switch piece.Type {
+		case Pawn, Rook, Queen:
+			return false
+		case Knight:
+			minors++
+		case Bishop:
+			minors++
+			bishopColors[(sq.File()+sq.Rank())%2] = true
+		}
+	}
+
+	if minors <= 1 {
+		return true
+	}
+
+	onlyBishops := p.count(Piece{Knight, White})+p.count(Piece{Knight, Black}) == 0
+
+	return onlyBishops && !(bishopColors[0] && bishopColors[1])
+}
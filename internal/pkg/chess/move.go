@@ -0,0 +1,399 @@
+package chess
+
+import (
+	"errors"
+)
+
+// Move is a move from one square to another.
+// Promotion is set to the piece type a pawn is promoted to, if any.
+// Castling is represented as a king move by two squares.
+type Move struct {
+	From      Square
+	To        Square
+	Promotion PieceType
+}
+
+var (
+	knightOffsets = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingOffsets   = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	bishopRays    = [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	rookRays      = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	promotions    = []PieceType{Queen, Rook, Bishop, Knight}
+)
+
+// String returns the move in UCI notation, e.g. "e2e4" or "e7e8q".
+func (m Move) String() string {
+	res := m.From.String() + m.To.String()
+
+	if m.Promotion != NoPieceType {
+		res += string(pieceSymbols[m.Promotion])
+	}
+
+	return res
+}
+
+// ParseMove parses a move in UCI notation and checks whether it is legal
+// in the position.
+func (p *Position) ParseMove(uci string) (Move, error) {
+	if len(uci) != 4 && len(uci) != 5 {
+		return Move{}, errors.New("invalid move: " + uci)
+	}
+
+	from, e1 := ParseSquare(uci[0:2])
+	to, e2 := ParseSquare(uci[2:4])
+
+	if e1 != nil || e2 != nil {
+		return Move{}, errors.New("invalid move: " + uci)
+	}
+
+	move := Move{From: from, To: to}
+
+	if len(uci) == 5 {
+		piece, ok := parsePiece(uci[4])
+
+		if !ok || piece.Color != Black || piece.Type == Pawn || piece.Type == King {
+			return Move{}, errors.New("invalid promotion: " + uci)
+		}
+
+		move.Promotion = piece.Type
+	}
+
+	for _, m := range p.LegalMoves() {
+		if m == move {
+			return move, nil
+		}
+	}
+
+	return Move{}, errors.New("illegal move: " + uci)
+}
+
+// LegalMoves returns all legal moves in the position.
+func (p *Position) LegalMoves() []Move {
+	moves := make([]Move, 0, 64)
+
+	for _, m := range p.pseudoLegalMoves() {
+		next := p.Play(m)
+
+		if !next.isAttacked(next.king(p.turn), next.turn) {
+			moves = append(moves, m)
+		}
+	}
+
+	return moves
+}
+
+// InCheck returns whether the side to move is in check.
+func (p *Position) InCheck() bool {
+	return p.isAttacked(p.king(p.turn), p.turn.Other())
+}
+
+// Play plays the move and returns the resulting position.
+// The move is expected to be legal.
+func (p *Position) Play(m Move) *Position {
+	next := *p
+	piece := p.board[m.From]
+	captured := p.board[m.To]
+
+	next.board[m.From] = Piece{}
+	next.board[m.To] = piece
+	next.ep = NoSquare
+	next.halfmove++
+
+	if piece.Type == Pawn || captured.Type != NoPieceType {
+		next.halfmove = 0
+	}
+
+	if piece.Type == Pawn {
+		if m.To == p.ep {
+			next.board[square(m.To.File(), m.From.Rank())] = Piece{}
+		}
+
+		if diff := int(m.To) - int(m.From); diff == 16 || diff == -16 {
+			next.ep = Square((int(m.To) + int(m.From)) / 2)
+		}
+
+		if m.Promotion != NoPieceType {
+			next.board[m.To] = Piece{m.Promotion, piece.Color}
+		}
+	}
+
+	if piece.Type == King {
+		if diff := int(m.To) - int(m.From); diff == 2 || diff == -2 {
+			rank := m.From.Rank()
+			rookFrom, rookTo := square(7, rank), square(5, rank)
+
+			if diff < 0 {
+				rookFrom, rookTo = square(0, rank), square(3, rank)
+			}
+
+			next.board[rookTo] = next.board[rookFrom]
+			next.board[rookFrom] = Piece{}
+		}
+	}
+
+	next.castling &^= castlingMask(m.From) | castlingMask(m.To)
+
+	if p.turn == Black {
+		next.fullmove++
+	}
+
+	next.turn = p.turn.Other()
+
+	if next.ep != NoSquare && !next.canCaptureEnPassant() {
+		next.ep = NoSquare
+	}
+
+	return &next
+}
+
+func (p *Position) pseudoLegalMoves() []Move {
+	moves := make([]Move, 0, 64)
+
+	for idx, piece := range p.board {
+		from := Square(idx)
+
+		if piece.Type == NoPieceType || piece.Color != p.turn {
+			continue
+		}
+
+		switch piece.Type {
+		case Pawn:
+			moves = p.pawnMoves(moves, from)
+		case Knight:
+			moves = p.stepMoves(moves, from, knightOffsets)
+		case Bishop:
+			moves = p.slideMoves(moves, from, bishopRays)
+		case Rook:
+			moves = p.slideMoves(moves, from, rookRays)
+		case Queen:
+			moves = p.slideMoves(moves, from, bishopRays)
+			moves = p.slideMoves(moves, from, rookRays)
+		case King:
+			moves = p.stepMoves(moves, from, kingOffsets)
+			moves = p.castlingMoves(moves, from)
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) pawnMoves(moves []Move, from Square) []Move {
+	dir, startRank, lastRank := 1, 1, 7
+
+	if p.turn == Black {
+		dir, startRank, lastRank = -1, 6, 0
+	}
+
+	add := func(to Square) {
+		if to.Rank() == lastRank {
+			for _, promo := range promotions {
+				moves = append(moves, Move{from, to, promo})
+			}
+		} else {
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+
+	if to, ok := offset(from, 0, dir); ok && p.board[to].Type == NoPieceType {
+		add(to)
+
+		if to2, ok := offset(to, 0, dir); ok && from.Rank() == startRank && p.board[to2].Type == NoPieceType {
+			add(to2)
+		}
+	}
+
+	for _, df := range []int{-1, 1} {
+		to, ok := offset(from, df, dir)
+
+		if !ok {
+			continue
+		}
+
+		if target := p.board[to]; (target.Type != NoPieceType && target.Color != p.turn) || to == p.ep {
+			add(to)
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) stepMoves(moves []Move, from Square, offsets [][2]int) []Move {
+	for _, o := range offsets {
+		to, ok := offset(from, o[0], o[1])
+
+		if ok && (p.board[to].Type == NoPieceType || p.board[to].Color != p.turn) {
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) slideMoves(moves []Move, from Square, rays [][2]int) []Move {
+	for _, r := range rays {
+		to, ok := offset(from, r[0], r[1])
+
+		for ok {
+			target := p.board[to]
+
+			if target.Type == NoPieceType || target.Color != p.turn {
+				moves = append(moves, Move{From: from, To: to})
+			}
+
+			if target.Type != NoPieceType {
+				break
+			}
+
+			to, ok = offset(to, r[0], r[1])
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) castlingMoves(moves []Move, from Square) []Move {
+	rank, kingside, queenside := 0, whiteKingside, whiteQueenside
+	enemy := p.turn.Other()
+
+	if p.turn == Black {
+		rank, kingside, queenside = 7, blackKingside, blackQueenside
+	}
+
+	if from != square(4, rank) || p.isAttacked(from, enemy) {
+		return moves
+	}
+
+	empty := func(files ...int) bool {
+		for _, f := range files {
+			if p.board[square(f, rank)].Type != NoPieceType {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	rook := Piece{Rook, p.turn}
+
+	if p.castling&kingside != 0 && p.board[square(7, rank)] == rook && empty(5, 6) && !p.isAttacked(square(5, rank), enemy) {
+		moves = append(moves, Move{From: from, To: square(6, rank)})
+	}
+
+	if p.castling&queenside != 0 && p.board[square(0, rank)] == rook && empty(1, 2, 3) && !p.isAttacked(square(3, rank), enemy) {
+		moves = append(moves, Move{From: from, To: square(2, rank)})
+	}
+
+	return moves
+}
+
+// isAttacked returns whether the square is attacked by any piece of the given color.
+func (p *Position) isAttacked(sq Square, by Color) bool {
+	if sq == NoSquare {
+		return false
+	}
+
+	is := func(s Square, types ...PieceType) bool {
+		piece := p.board[s]
+
+		for _, t := range types {
+			if piece.Type == t && piece.Color == by {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	pawnDir := -1
+
+	if by == Black {
+		pawnDir = 1
+	}
+
+	for _, df := range []int{-1, 1} {
+		if s, ok := offset(sq, df, pawnDir); ok && is(s, Pawn) {
+			return true
+		}
+	}
+
+	for _, o := range knightOffsets {
+		if s, ok := offset(sq, o[0], o[1]); ok && is(s, Knight) {
+			return true
+		}
+	}
+
+	for _, o := range kingOffsets {
+		if s, ok := offset(sq, o[0], o[1]); ok && is(s, King) {
+			return true
+		}
+	}
+
+	ray := func(rays [][2]int, types ...PieceType) bool {
+		for _, r := range rays {
+			s, ok := offset(sq, r[0], r[1])
+
+			for ok {
+				if p.board[s].Type != NoPieceType {
+					if is(s, types...) {
+						return true
+					}
+
+					break
+				}
+
+				s, ok = offset(s, r[0], r[1])
+			}
+		}
+
+		return false
+	}
+
+	return ray(bishopRays, Bishop, Queen) || ray(rookRays, Rook, Queen)
+}
+
+// canCaptureEnPassant returns whether a pawn of the side to move stands next
+// to the en passant square and could capture onto it.
+func (p *Position) canCaptureEnPassant() bool {
+	dir := -1
+
+	if p.turn == Black {
+		dir = 1
+	}
+
+	for _, df := range []int{-1, 1} {
+		if s, ok := offset(p.ep, df, dir); ok && p.board[s] == (Piece{Pawn, p.turn}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func castlingMask(sq Square) uint8 {
+	switch sq {
+	case square(4, 0):
+		return whiteKingside | whiteQueenside
+	case square(7, 0):
+		return whiteKingside
+	case square(0, 0):
+		return whiteQueenside
+	case square(4, 7):
+		return blackKingside | blackQueenside
+	case square(7, 7):
+		return blackKingside
+	case square(0, 7):
+		return blackQueenside
+	}
+
+	return 0
+}
+
+func offset(sq Square, df int, dr int) (Square, bool) {
+	file, rank := sq.File()+df, sq.Rank()+dr
+
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return NoSquare, false
+	}
+
+	return square(file, rank), true
+}
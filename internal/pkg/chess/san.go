@@ -0,0 +1,109 @@
+package chess
+
+import (
+	"errors"
+	"strings"
+)
+
+// SAN returns the move in standard algebraic notation, e.g. "Nf3", "exd5", "O-O" or "e8=Q+".
+// The move is expected to be legal in the position.
+func (p *Position) SAN(m Move) string {
+	var sb strings.Builder
+	piece := p.board[m.From]
+
+	if diff := int(m.To) - int(m.From); piece.Type == King && (diff == 2 || diff == -2) {
+		if diff > 0 {
+			sb.WriteString("O-O")
+		} else {
+			sb.WriteString("O-O-O")
+		}
+	} else {
+		capture := p.board[m.To].Type != NoPieceType || (piece.Type == Pawn && m.To == p.ep)
+
+		if piece.Type == Pawn {
+			if capture {
+				sb.WriteByte(byte('a' + m.From.File()))
+			}
+		} else {
+			sb.WriteByte(Piece{piece.Type, White}.Symbol())
+			sb.WriteString(p.disambiguate(m))
+		}
+
+		if capture {
+			sb.WriteByte('x')
+		}
+
+		sb.WriteString(m.To.String())
+
+		if m.Promotion != NoPieceType {
+			sb.WriteByte('=')
+			sb.WriteByte(Piece{m.Promotion, White}.Symbol())
+		}
+	}
+
+	next := p.Play(m)
+
+	if next.InCheck() {
+		if len(next.LegalMoves()) == 0 {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('+')
+		}
+	}
+
+	return sb.String()
+}
+
+// ParseSAN parses a move in standard algebraic notation and checks whether it is
+// legal in the position. Check and annotation symbols are optional, castling may
+// be written with zeros and the '=' before a promotion may be omitted.
+func (p *Position) ParseSAN(san string) (Move, error) {
+	want := normalizeSAN(san)
+
+	if want == "" {
+		return Move{}, errors.New("invalid move: " + san)
+	}
+
+	for _, m := range p.LegalMoves() {
+		if normalizeSAN(p.SAN(m)) == want {
+			return m, nil
+		}
+	}
+
+	return Move{}, errors.New("illegal move: " + san)
+}
+
+// normalizeSAN removes the optional parts of a move in standard algebraic notation.
+func normalizeSAN(san string) string {
+	san = strings.TrimRight(strings.TrimSpace(san), "+#!?")
+	san = strings.ReplaceAll(san, "0", "O")
+	return strings.ReplaceAll(san, "=", "")
+}
+
+// disambiguate returns the file, rank or square of the origin of the move,
+// if another piece of the same type could move to the same square.
+func (p *Position) disambiguate(m Move) string {
+	piece := p.board[m.From]
+	ambiguous, sameFile, sameRank := false, false, false
+
+	for _, other := range p.LegalMoves() {
+		if other.To != m.To || other.From == m.From || p.board[other.From] != piece {
+			continue
+		}
+
+		ambiguous = true
+		sameFile = sameFile || other.From.File() == m.From.File()
+		sameRank = sameRank || other.From.Rank() == m.From.Rank()
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return m.From.String()[:1]
+	case !sameRank:
+		return m.From.String()[1:]
+	default:
+		return m.From.String()
+	}
+}
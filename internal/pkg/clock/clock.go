@@ -0,0 +1,149 @@
+// Package clock implements chess time controls and the clocks which are used
+// to enforce them during a game.
+package clock
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeControl describes how much time a player has to make their moves.
+//
+// A time control is written as "[moves/]base[+increment]" where base and
+// increment are given in seconds. For example "40/60+0.6" grants 60 seconds
+// for every 40 moves and adds 0.6 seconds after each move, while "10+0.1"
+// grants 10 seconds for the whole game with an increment of 0.1 seconds.
+type TimeControl struct {
+	Moves     int           // The number of moves per period or 0 if the base time has to last for the whole game.
+	Base      time.Duration // The time granted per period.
+	Increment time.Duration // The time added after each move.
+}
+
+// Clock tracks the remaining time of a single player.
+type Clock struct {
+	control   TimeControl
+	remaining time.Duration
+	played    int
+}
+
+// Parse parses a time control string in the format "[moves/]base[+increment]".
+func Parse(tc string) (*TimeControl, error) {
+	var control TimeControl
+	rest := strings.TrimSpace(tc)
+
+	if moves, base, found := strings.Cut(rest, "/"); found {
+		n, err := strconv.Atoi(moves)
+
+		if err != nil || n <= 0 {
+			return nil, errors.New("Invalid number of moves in time control: " + tc)
+		}
+
+		control.Moves = n
+		rest = base
+	}
+
+	base, inc, hasInc := strings.Cut(rest, "+")
+
+	if d, err := parseSeconds(base); err != nil || d <= 0 {
+		return nil, errors.New("Invalid base time in time control: " + tc)
+	} else {
+		control.Base = d
+	}
+
+	if hasInc {
+		if d, err := parseSeconds(inc); err != nil || d < 0 {
+			return nil, errors.New("Invalid increment in time control: " + tc)
+		} else {
+			control.Increment = d
+		}
+	}
+
+	return &control, nil
+}
+
+// String returns the time control in the format "[moves/]base[+increment]".
+func (tc TimeControl) String() string {
+	res := formatSeconds(tc.Base)
+
+	if tc.Moves > 0 {
+		res = strconv.Itoa(tc.Moves) + "/" + res
+	}
+
+	if tc.Increment > 0 {
+		res += "+" + formatSeconds(tc.Increment)
+	}
+
+	return res
+}
+
+// New returns a clock for the given time control.
+// The clock starts with the base time of the time control.
+func New(tc TimeControl) *Clock {
+	return &Clock{
+		control:   tc,
+		remaining: tc.Base,
+	}
+}
+
+// Remaining returns the time left on the clock.
+func (c *Clock) Remaining() time.Duration {
+	return c.remaining
+}
+
+// Increment returns the time which is added to the clock after each move.
+func (c *Clock) Increment() time.Duration {
+	return c.control.Increment
+}
+
+// MovesToGo returns the number of moves until the next time period starts.
+// If the time control has no periods, 0 is returned.
+func (c *Clock) MovesToGo() int {
+	if c.control.Moves == 0 {
+		return 0
+	}
+
+	return c.control.Moves - c.played%c.control.Moves
+}
+
+// Punch stops the clock after a move which took elapsed time.
+// If the move took longer than the remaining time plus the allowed overhead,
+// the flag falls and false is returned. Otherwise the elapsed time is
+// subtracted, the increment is added and a new period is started if required.
+func (c *Clock) Punch(elapsed time.Duration, overhead time.Duration) bool {
+	if elapsed > c.remaining+overhead {
+		c.remaining = 0
+		return false
+	}
+
+	c.remaining -= elapsed
+
+	if c.remaining < 0 {
+		c.remaining = 0
+	}
+
+	c.remaining += c.control.Increment
+	c.played++
+
+	if c.control.Moves > 0 && c.played%c.control.Moves == 0 {
+		c.remaining += c.control.Base
+	}
+
+	return true
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errors.New("invalid number of seconds: " + s)
+	}
+
+	return time.Duration(math.Round(f * float64(time.Second))), nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
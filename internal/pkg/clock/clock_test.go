@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+type tc_io struct {
+	in  string
+	out TimeControl
+	str string
+}
+
+var controls = []tc_io{
+	{"10+0.1", TimeControl{Base: 10 * time.Second, Increment: 100 * time.Millisecond}, "10+0.1"},
+	{"40/60+0.6", TimeControl{Moves: 40, Base: 60 * time.Second, Increment: 600 * time.Millisecond}, "40/60+0.6"},
+	{"40/60", TimeControl{Moves: 40, Base: 60 * time.Second}, "40/60"},
+	{"300", TimeControl{Base: 300 * time.Second}, "300"},
+	{"0.5+0", TimeControl{Base: 500 * time.Millisecond}, "0.5"},
+}
+
+var invalidControls = []string{
+	"",
+	"+1",
+	"0+1",
+	"40/",
+	"x/60",
+	"0/60",
+	"60+x",
+	"60+-1",
+}
+
+func TestParse(t *testing.T) {
+	for _, io := range controls {
+		tc, err := Parse(io.in)
+
+		if err != nil {
+			t.Errorf("Expected %s to be valid, got %v", io.in, err)
+			continue
+		}
+
+		if *tc != io.out {
+			t.Errorf("Expected %v, got %v", io.out, *tc)
+		}
+
+		if tc.String() != io.str {
+			t.Errorf("Expected %s, got %s", io.str, tc.String())
+		}
+	}
+
+	for _, in := range invalidControls {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Expected %s to be invalid", in)
+		}
+	}
+}
+
+func TestPunch(t *testing.T) {
+	c := New(TimeControl{Moves: 2, Base: time.Second, Increment: 100 * time.Millisecond})
+
+	if c.MovesToGo() != 2 {
+		t.Errorf("Expected 2 moves to go, got %d", c.MovesToGo())
+	}
+
+	if !c.Punch(400*time.Millisecond, 0) || c.Remaining() != 700*time.Millisecond {
+		t.Errorf("Expected 700ms remaining, got %v", c.Remaining())
+	}
+
+	if !c.Punch(750*time.Millisecond, 100*time.Millisecond) || c.Remaining() != 1100*time.Millisecond {
+		t.Errorf("Expected 1100ms remaining after new period, got %v", c.Remaining())
+	}
+
+	if c.MovesToGo() != 2 {
+		t.Errorf("Expected 2 moves to go, got %d", c.MovesToGo())
+	}
+
+	if c.Punch(1300*time.Millisecond, 100*time.Millisecond) {
+		t.Errorf("Expected flag to fall")
+	}
+
+	if c.Remaining() != 0 {
+		t.Errorf("Expected no time remaining, got %v", c.Remaining())
+	}
+}
@@ -2,6 +2,7 @@ package com
 
 import (
 	"encoding/json"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -25,8 +26,8 @@ type Client struct {
 	// Errors is a channel that distributes any errors that occur during the connection.
 	Errors chan error
 
-	// ping is the last measured ping to the server.
-	ping int64
+	// ping is the last measured ping to the server, which is updated atomically by the sender.
+	ping *int64
 
 	// flow is the flow that is used to parse messages.
 	flow Flow
@@ -50,13 +51,14 @@ func Connect(url string, flow Flow) (*Client, error) {
 		Messages: msgChan,
 		Commands: cmdChan,
 		Errors:   errChan,
-		ping:     -1,
+		ping:     new(int64),
 		flow:     flow,
 		conn:     conn,
 	}
 
 	go handleReceive(msgChan, errChan, conn, flow)
-	go handleSend(cmdChan, errChan, conn, &client.ping)
+	*client.ping = -1
+	go handleSend(cmdChan, errChan, conn, client.ping)
 
 	return &client, nil
 }
@@ -66,19 +68,56 @@ func (c Client) Close() error {
 	return c.conn.Close()
 }
 
+// Shutdown writes all commands sent before, closes the connection gracefully and closes it.
+// It waits at most the timeout for the commands to be written.
+func (c Client) Shutdown(timeout time.Duration) error {
+	done := make(chan struct{})
+	expired := time.After(timeout)
+
+	select {
+	case c.Commands <- closeCmd{done}:
+		select {
+		case <-done:
+		case <-expired:
+		}
+	case <-expired:
+	}
+
+	return c.conn.Close()
+}
+
+// closeCmd asks the sender to close the connection after all commands sent before were written.
+type closeCmd struct {
+	done chan struct{}
+}
+
+func (closeCmd) Encode() string {
+	return ""
+}
+
 // Ping returns the last measured ping to the server.
 // If the ping is not yet measured, 0 is returned.
 func (c Client) Ping() int64 {
-	if c.ping < 0 {
+	ping := atomic.LoadInt64(c.ping)
+
+	if ping < 0 {
 		return 0
 	}
 
-	return c.ping
+	return ping
 }
 
 func handleSend(cmdChan chan Command, errChan chan error, conn *websocket.Conn, ping *int64) {
 	for {
 		cmd := <-cmdChan
+
+		if c, ok := cmd.(closeCmd); ok {
+			msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+			close(c.done)
+			break
+		}
+
 		message := cmd.Encode()
 		start := time.Now().UnixMilli()
 
@@ -88,7 +127,7 @@ func handleSend(cmdChan chan Command, errChan chan error, conn *websocket.Conn,
 		}
 
 		end := time.Now().UnixMilli()
-		*ping = end - start
+		atomic.StoreInt64(ping, end-start)
 	}
 }
 
@@ -0,0 +1,120 @@
+// Package outbox persists commands on disk until the server acknowledged them.
+// Commands which were not acknowledged, because the connection dropped or the
+// programm exited, can be replayed after reconnecting.
+package outbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/com"
+)
+
+// Outbox stores encoded commands as files in a directory.
+// Each command is identified by an id, which is also used as file name.
+type Outbox struct {
+	dir   string
+	mutex sync.Mutex
+	last  int64
+}
+
+// Entry is a stored command which can be sent again.
+type Entry struct {
+	Id      string // The id of the command.
+	encoded string
+}
+
+// Open returns the outbox in the given directory.
+// The directory is created if it does not exist.
+func Open(dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Outbox{dir: dir}, nil
+}
+
+// NextID returns a new unique id for a command.
+// Ids are ordered by the time they were created.
+func (o *Outbox) NextID() string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now := time.Now().UnixNano()
+
+	if now <= o.last {
+		now = o.last + 1
+	}
+
+	o.last = now
+	return fmt.Sprintf("%020d", now)
+}
+
+// Put stores the command with the given id.
+// The command is written to a temporary file first, so a crash never leaves an incomplete entry.
+func (o *Outbox) Put(id string, cmd com.Command) error {
+	tmp := filepath.Join(o.dir, id+".tmp")
+
+	if err := os.WriteFile(tmp, []byte(cmd.Encode()), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, o.path(id))
+}
+
+// Remove deletes the command with the given id.
+// Removing an unknown id is not an error.
+func (o *Outbox) Remove(id string) error {
+	if err := os.Remove(o.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Pending returns all stored commands ordered by their id.
+func (o *Outbox) Pending() ([]Entry, error) {
+	files, err := os.ReadDir(o.dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(files))
+
+	for _, f := range files {
+		id, found := strings.CutSuffix(f.Name(), ".json")
+
+		if f.IsDir() || !found {
+			continue
+		}
+
+		buf, err := os.ReadFile(filepath.Join(o.dir, f.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{Id: id, encoded: string(buf)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Id < entries[j].Id
+	})
+
+	return entries, nil
+}
+
+// Encode returns the stored representation of the command.
+func (e Entry) Encode() string {
+	return e.encoded
+}
+
+func (o *Outbox) path(id string) string {
+	return filepath.Join(o.dir, id+".json")
+}
@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"testing"
+)
+
+type testCmd string
+
+func (c testCmd) Encode() string {
+	return string(c)
+}
+
+func TestOutbox(t *testing.T) {
+	box, err := Open(t.TempDir())
+
+	if err != nil {
+		t.Fatalf("Expected outbox to open, got %v", err)
+	}
+
+	ids := []string{box.NextID(), box.NextID(), box.NextID()}
+
+	for idx, id := range ids {
+		if idx > 0 && id <= ids[idx-1] {
+			t.Errorf("Expected %s to be greater than %s", id, ids[idx-1])
+		}
+
+		if err := box.Put(id, testCmd("cmd "+id)); err != nil {
+			t.Fatalf("Expected %s to be stored, got %v", id, err)
+		}
+	}
+
+	box.Remove(ids[1])
+	box.Remove("unknown")
+
+	pending, err := box.Pending()
+
+	if err != nil {
+		t.Fatalf("Expected pending entries, got %v", err)
+	}
+
+	if len(pending) != 2 || pending[0].Id != ids[0] || pending[1].Id != ids[2] {
+		t.Fatalf("Expected entries %s and %s, got %v", ids[0], ids[2], pending)
+	}
+
+	if enc := pending[1].Encode(); enc != "cmd "+ids[2] {
+		t.Errorf("Expected %q, got %q", "cmd "+ids[2], enc)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"strings"
 )
 
+// UpdateReqCmd asks the server to send the current state of the game of the player as UpdateMsg.
 type UpdateReqCmd struct {
-	Key string `json:"command"`
+	Key    string `json:"key"`
+	Game   string `json:"game,omitempty"`
+	Player string `json:"player"`
 }
 
 type CheckInCmd struct {
@@ -31,9 +34,13 @@ func (c MoveCmd) Encode() string {
 	return encode(c)
 }
 
+// BuildUpdateReqCmd returns an UpdateReqCmd for the player.
+// The game may be empty if the player only takes part in a single game.
 func BuildUpdateReqCmd(game string, player string) UpdateReqCmd {
 	return UpdateReqCmd{
-		Key: "update-req-msg",
+		Key:    "update-req-msg",
+		Game:   game,
+		Player: player,
 	}
 }
 
@@ -3,6 +3,10 @@ package playflow
 import (
 	"encoding/json"
 	"errors"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/chess"
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
 )
 
 type Flow struct {
@@ -21,6 +25,12 @@ func (f Flow) Parse(key string, data []byte) (any, error) {
 		return parse[MoveRequestMsg](data)
 	case "update-msg":
 		return parse[UpdateMsg](data)
+	case "game-over-msg":
+		return parse[GameOverMsg](data)
+	case "result-msg":
+		return parse[ResultMsg](data)
+	case "error-msg":
+		return parse[ErrorMsg](data)
 	default:
 		return nil, errors.New("invalid key")
 	}
@@ -32,15 +42,136 @@ func parse[T any](data []byte) (T, error) {
 	return m, err
 }
 
+// MoveRequestMsg is sent by the server when the player has to move.
+// If the game is played with a clock, the remaining times and increments of
+// both players are sent in ms. Otherwise the time is the fixed time for the move.
 type MoveRequestMsg struct {
+	Key       string   `json:"key"`
+	History   []string `json:"history"`
+	Time      int      `json:"time"`
+	Start     string   `json:"start"`
+	WTime     int      `json:"wtime"`
+	BTime     int      `json:"btime"`
+	WInc      int      `json:"winc"`
+	BInc      int      `json:"binc"`
+	MovesToGo int      `json:"movestogo"`
+}
+
+// Limits returns the search limits for the requested move.
+// The overhead is subtracted from the times of both players to compensate for the
+// network latency, but at least one millisecond is left.
+// If the remaining times are not known, the fixed move time is used.
+func (m MoveRequestMsg) Limits(overhead time.Duration) uci.Limits {
+	ms := int(overhead.Milliseconds())
+	subtract := func(t int) int {
+		if t-ms < 1 {
+			return 1
+		}
+
+		return t - ms
+	}
+
+	if m.WTime <= 0 && m.BTime <= 0 {
+		return uci.Limits{MoveTime: subtract(m.Time)}
+	}
+
+	return uci.Limits{
+		WTime:     subtract(m.WTime),
+		BTime:     subtract(m.BTime),
+		WInc:      m.WInc,
+		BInc:      m.BInc,
+		MovesToGo: m.MovesToGo,
+	}
+}
+
+// LimitsAfter returns the search limits for the requested move like Limits, for a
+// search which starts the elapsed time after the request was received.
+// The elapsed time is only subtracted from the clock of the side to move, or from
+// the fixed move time, but at least one millisecond is left.
+func (m MoveRequestMsg) LimitsAfter(overhead time.Duration, elapsed time.Duration) uci.Limits {
+	limits := m.Limits(overhead)
+	ms := int(elapsed.Milliseconds())
+	spend := func(t *int) {
+		if *t -= ms; *t < 1 {
+			*t = 1
+		}
+	}
+
+	switch {
+	case limits.MoveTime > 0:
+		spend(&limits.MoveTime)
+	case m.WhiteToMove():
+		spend(&limits.WTime)
+	default:
+		spend(&limits.BTime)
+	}
+
+	return limits
+}
+
+// WhiteToMove returns whether white has to move in the requested position.
+// An empty or invalid start position is treated as the standard starting position.
+func (m MoveRequestMsg) WhiteToMove() bool {
+	white := true
+
+	if pos, err := chess.ParseFEN(m.Start); err == nil {
+		white = pos.Turn() == chess.White
+	}
+
+	return white == (len(m.History)%2 == 0)
+}
+
+type UpdateMsg struct {
 	Key     string   `json:"key"`
 	History []string `json:"history"`
-	Time    int      `json:"time"`
 	Start   string   `json:"start"`
 }
 
-type UpdateMsg struct {
+// GameOverMsg is sent by the server when the game ended and no further moves are requested.
+// It is followed by a ResultMsg unless the game was aborted.
+type GameOverMsg struct {
 	Key     string   `json:"key"`
+	Reason  string   `json:"reason"`
 	History []string `json:"history"`
 	Start   string   `json:"start"`
 }
+
+// ResultMsg is sent by the server with the result of a finished game.
+type ResultMsg struct {
+	Key     string `json:"key"`
+	Outcome string `json:"outcome"` // The PGN style outcome, e.g. "1-0", "0-1" or "1/2-1/2".
+	Winner  string `json:"winner"`  // The id of the winning player or empty for a draw.
+	Reason  string `json:"reason"`
+}
+
+// Describe returns the result from the perspective of the player with the given id,
+// e.g. "Won 1-0 (checkmate)".
+func (m ResultMsg) Describe(player string) string {
+	verdict := "Draw"
+
+	if m.Winner == player {
+		verdict = "Won"
+	} else if m.Winner != "" {
+		verdict = "Lost"
+	}
+
+	return verdict + " " + m.Outcome + " (" + m.Reason + ")"
+}
+
+// Replay returns the game described by the start position and the moves sent by
+// the server. An empty start position is the standard starting position.
+func Replay(start string, history []string) (*chess.Game, error) {
+	if start == "" {
+		start = chess.StartFEN
+	}
+
+	return chess.Replay(start, history...)
+}
+
+// ErrorMsg is sent by the server if a command could not be processed.
+// A fatal error ends the game for the player.
+type ErrorMsg struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	Fatal   bool   `json:"fatal"`
+}
@@ -0,0 +1,103 @@
+package playflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/uci"
+)
+
+type parse_io struct {
+	key  string
+	data string
+	out  any
+}
+
+var messages = []parse_io{
+	{"game-over-msg", `{"key":"game-over-msg","reason":"checkmate","history":["f2f3"],"start":"startpos"}`, GameOverMsg{Key: "game-over-msg", Reason: "checkmate", History: []string{"f2f3"}, Start: "startpos"}},
+	{"result-msg", `{"key":"result-msg","outcome":"1-0","winner":"p1","reason":"checkmate"}`, ResultMsg{Key: "result-msg", Outcome: "1-0", Winner: "p1", Reason: "checkmate"}},
+	{"error-msg", `{"key":"error-msg","message":"illegal move","fatal":true}`, ErrorMsg{Key: "error-msg", Message: "illegal move", Fatal: true}},
+}
+
+type limits_io struct {
+	msg MoveRequestMsg
+	out uci.Limits
+}
+
+var limits = []limits_io{
+	{MoveRequestMsg{Time: 1000}, uci.Limits{MoveTime: 900}},
+	{MoveRequestMsg{Time: 50}, uci.Limits{MoveTime: 1}},
+	{MoveRequestMsg{Time: 1000, WTime: 60000, BTime: 30000, WInc: 1000, BInc: 500}, uci.Limits{WTime: 59900, BTime: 29900, WInc: 1000, BInc: 500}},
+	{MoveRequestMsg{WTime: 80, BTime: 5000, MovesToGo: 10}, uci.Limits{WTime: 1, BTime: 4900, MovesToGo: 10}},
+}
+
+func TestLimits(t *testing.T) {
+	for _, io := range limits {
+		if out := io.msg.Limits(100 * time.Millisecond); out != io.out {
+			t.Errorf("Expected %+v, got %+v", io.out, out)
+		}
+	}
+}
+
+func TestLimitsAfter(t *testing.T) {
+	black := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"
+	cases := []limits_io{
+		{MoveRequestMsg{Time: 1000}, uci.Limits{MoveTime: 400}},
+		{MoveRequestMsg{WTime: 60000, BTime: 30000}, uci.Limits{WTime: 59400, BTime: 29900}},
+		{MoveRequestMsg{WTime: 60000, BTime: 30000, History: []string{"e2e4"}}, uci.Limits{WTime: 59900, BTime: 29400}},
+		{MoveRequestMsg{WTime: 60000, BTime: 30000, Start: black}, uci.Limits{WTime: 59900, BTime: 29400}},
+		{MoveRequestMsg{WTime: 300, BTime: 30000}, uci.Limits{WTime: 1, BTime: 29900}},
+	}
+
+	for _, io := range cases {
+		if out := io.msg.LimitsAfter(100*time.Millisecond, 500*time.Millisecond); out != io.out {
+			t.Errorf("Expected %+v, got %+v", io.out, out)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, io := range messages {
+		msg, err := NewFlow().Parse(io.key, []byte(io.data))
+
+		if err != nil {
+			t.Errorf("Expected %s to parse, got %v", io.key, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(msg, io.out) {
+			t.Errorf("Expected %+v, got %+v", io.out, msg)
+		}
+	}
+
+	if _, err := NewFlow().Parse("unknown", []byte(`{}`)); err == nil {
+		t.Errorf("Expected unknown key to be rejected")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	msg := ResultMsg{Outcome: "1-0", Winner: "p1", Reason: "checkmate"}
+
+	for player, out := range map[string]string{"p1": "Won 1-0 (checkmate)", "p2": "Lost 1-0 (checkmate)"} {
+		if d := msg.Describe(player); d != out {
+			t.Errorf("Expected %s, got %s", out, d)
+		}
+	}
+
+	if d := (ResultMsg{Outcome: "1/2-1/2", Reason: "stalemate"}).Describe("p1"); d != "Draw 1/2-1/2 (stalemate)" {
+		t.Errorf("Expected a draw, got %s", d)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	game, err := Replay("", []string{"e2e4", "e7e5"})
+
+	if err != nil || len(game.Moves()) != 2 {
+		t.Errorf("Expected two moves from the starting position, got %v", err)
+	}
+
+	if _, err := Replay("", []string{"e2e5"}); err == nil {
+		t.Errorf("Expected an illegal move to be rejected")
+	}
+}
@@ -20,13 +20,97 @@ type LogEntry struct {
 // Log is a slice of log entries.
 type Log [][]LogEntry
 
+// Outcome is the PGN style outcome of a game.
+type Outcome string
+
+const (
+	WhiteWins    Outcome = "1-0"     // The engine playing white won the game.
+	BlackWins    Outcome = "0-1"     // The engine playing black won the game.
+	Draw         Outcome = "1/2-1/2" // The game ended in a draw.
+	Unterminated Outcome = "*"       // The game ended without a result.
+)
+
+// FailureKind describes what went wrong when a game could not be finished.
+type FailureKind string
+
+const (
+	LaunchFailure FailureKind = "launch" // The engine could not be started or did not become ready.
+	CrashFailure  FailureKind = "crash"  // The engine process exited during the game.
+	HangFailure   FailureKind = "hang"   // The engine did not respond in time.
+)
+
+// Failure contains the diagnostics of a game which could not be finished.
+type Failure struct {
+	Kind    FailureKind `json:"kind"`    // What went wrong.
+	Engine  int         `json:"engine"`  // The index of the engine that failed.
+	Message string      `json:"message"` // A description of the error.
+	Output  []string    `json:"output"`  // The last lines exchanged with the engine.
+}
+
+// GameResult is a struct that represents the result of a single game.
+// Failed games have an unterminated outcome and contain a failure.
+type GameResult struct {
+	White   int          `json:"white"`             // The index of the engine that played white.
+	Outcome Outcome      `json:"outcome"`           // The outcome of the game.
+	Reason  string       `json:"reason"`            // The reason why the game ended.
+	Failure *Failure     `json:"failure,omitempty"` // The diagnostics if the game failed.
+	Perf    [2]PerfStats `json:"perf"`              // The performance of both engines in the game.
+}
+
 // ReportCmd is a struct that represents a report command.
 // This command is used to report the results of a batch of games.
+// The games of the batch were already reported one by one using GameReportCmd.
+// Oversized batches are split into several chunks, which share the session.
+// The moves and logs are JSON encoded as []GameMoveHistory and []Log and compressed
+// as described by the encoding.
+// The performance statistics cover the whole batch and are repeated in each chunk.
 type ReportCmd struct {
-	Key     string            `json:"command"`
-	Session string            `json:"session"`
-	Moves   []GameMoveHistory `json:"moves"`
-	Logs    []Log             `json:"logs"`
+	Key      string       `json:"command"`
+	Id       string       `json:"id"`
+	Session  string       `json:"session"`
+	Chunk    int          `json:"chunk"`
+	Chunks   int          `json:"chunks"`
+	Encoding string       `json:"encoding"`
+	Moves    string       `json:"moves"`
+	Logs     string       `json:"logs"`
+	Results  []GameResult `json:"results"`
+	Perf     [2]PerfStats `json:"perf"`
+	Aborted  string       `json:"aborted,omitempty"` // The reason why the batch was aborted early, if it was.
+}
+
+// GameReportCmd is a struct that represents a game report command.
+// This command is used to report a single game as soon as it ended,
+// so finished games are not lost if the batch is interrupted.
+// The moves and logs are JSON encoded as GameMoveHistory and [][]LogEntry and compressed
+// as described by the encoding.
+type GameReportCmd struct {
+	Key      string     `json:"command"`
+	Id       string     `json:"id"`
+	Session  string     `json:"session"`
+	Encoding string     `json:"encoding"`
+	Moves    string     `json:"moves"`
+	Logs     string     `json:"logs"`
+	Result   GameResult `json:"result"`
+}
+
+// SuiteErrorCmd is a struct that represents a suite error command.
+// This command is used to reject a session whose suite can not be played,
+// e.g. because an engine does not support one of the configured options.
+type SuiteErrorCmd struct {
+	Key     string   `json:"command"`
+	Session string   `json:"session"`
+	Engine  int      `json:"engine"`  // The index of the engine which rejected the suite.
+	Message string   `json:"message"` // A description of the error.
+	Options []string `json:"options"` // The options the engine does not support.
+}
+
+// Limits are the resource limits of a test driver.
+// Zero values do not limit the resource.
+type Limits struct {
+	Concurrency  int `json:"concurrency"`  // The fixed number of concurrent games.
+	MaxCores     int `json:"maxCores"`     // The maximum number of cores used by the games.
+	MaxMemory    int `json:"maxMemory"`    // The maximum memory used by the games in MB.
+	ReserveCores int `json:"reserveCores"` // The number of cores kept free for other programs.
 }
 
 // RegisterCmd is a struct that represents a register command.
@@ -36,6 +120,24 @@ type RegisterCmd struct {
 	Name     string     `json:"name"`
 	DeviceId string     `json:"deviceId"`
 	Hardware sys.Device `json:"hardware"`
+	Limits   Limits     `json:"limits"`
+}
+
+// DeregisterCmd is a struct that represents a deregister command.
+// This command is used to tell the server that the test driver stops
+// and will not accept further sessions.
+type DeregisterCmd struct {
+	Key string `json:"command"`
+}
+
+// Encode returns a string representation of the command.
+func (c DeregisterCmd) Encode() string {
+	return encode(c)
+}
+
+// Encode returns a string representation of the command.
+func (c SuiteErrorCmd) Encode() string {
+	return encode(c)
 }
 
 // Encode returns a string representation of the command.
@@ -48,9 +150,14 @@ func (c ReportCmd) Encode() string {
 	return encode(c)
 }
 
-// BuildRegisterCmd returns a RegisterCmd.
+// Encode returns a string representation of the command.
+func (c GameReportCmd) Encode() string {
+	return encode(c)
+}
+
+// BuildRegisterCmd returns a RegisterCmd with the given resource limits.
 // It automatically fills in the name, device id and hardware fields.
-func BuildRegisterCmd() RegisterCmd {
+func BuildRegisterCmd(limits Limits) RegisterCmd {
 	hardware, identifier := sys.DeviceInfo()
 
 	return RegisterCmd{
@@ -58,16 +165,25 @@ func BuildRegisterCmd() RegisterCmd {
 		Name:     identifier.Name,
 		DeviceId: identifier.ID,
 		Hardware: hardware,
+		Limits:   limits,
+	}
+}
+
+// BuildDeregisterCmd returns a DeregisterCmd.
+func BuildDeregisterCmd() DeregisterCmd {
+	return DeregisterCmd{
+		Key: "deregister",
 	}
 }
 
-// BuildReportCmd returns a ReportCmd with the given parameters.
-func BuildReportCmd(session string, moves []GameMoveHistory, logs []Log) ReportCmd {
-	return ReportCmd{
-		Key:     "report",
+// BuildSuiteErrorCmd returns a SuiteErrorCmd for the session with the given parameters.
+func BuildSuiteErrorCmd(session string, engine int, message string, options []string) SuiteErrorCmd {
+	return SuiteErrorCmd{
+		Key:     "suite-error",
 		Session: session,
-		Moves:   moves,
-		Logs:    logs,
+		Engine:  engine,
+		Message: message,
+		Options: options,
 	}
 }
 
@@ -3,6 +3,7 @@ package testflow
 import (
 	"encoding/json"
 	"errors"
+	"strconv"
 )
 
 type version_t struct {
@@ -12,13 +13,15 @@ type version_t struct {
 }
 
 type time_t struct {
-	Type  string `json:"type"`
-	Value int    `json:"value"`
+	Type    string `json:"type"`
+	Value   int    `json:"value"`
+	Control string `json:"control"`
 }
 
 type options_t struct {
-	HashSize int `json:"hash"`
-	Threads  int `json:"threads"`
+	HashSize int                    `json:"hash"`
+	Threads  int                    `json:"threads"`
+	Custom   map[string]OptionValue `json:"custom"`
 }
 
 type engine_t struct {
@@ -33,6 +36,33 @@ type suite_t struct {
 	Name       string     `json:"name"`
 	Iterations int        `json:"iterations"`
 	Engines    []engine_t `json:"engines"`
+	Trace      bool       `json:"trace"` // Whether the progress of each search is reported.
+}
+
+// OptionValue is the value of a UCI option of an engine in a suite.
+// The server may send it as JSON string, number or boolean.
+type OptionValue string
+
+// UnmarshalJSON decodes a JSON string, number or boolean into its UCI representation.
+func (v *OptionValue) UnmarshalJSON(data []byte) error {
+	var raw any
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch raw := raw.(type) {
+	case string:
+		*v = OptionValue(raw)
+	case float64:
+		*v = OptionValue(strconv.FormatFloat(raw, 'f', -1, 64))
+	case bool:
+		*v = OptionValue(strconv.FormatBool(raw))
+	default:
+		return errors.New("Invalid option value: " + string(data))
+	}
+
+	return nil
 }
 
 type Flow struct {
@@ -51,6 +81,8 @@ func (f Flow) Parse(key string, data []byte) (any, error) {
 		return parse[RegisteredMsg](data)
 	case "start":
 		return parse[StartMsg](data)
+	case "ack":
+		return parse[AckMsg](data)
 	default:
 		return nil, errors.New("invalid key")
 	}
@@ -75,3 +107,9 @@ type StartMsg struct {
 	Suite                suite_t `json:"suite"`
 	RecommendedBatchSize int     `json:"recommendedBatchSize"`
 }
+
+// AckMsg is a message sent by the server to acknowledge that a report was stored.
+type AckMsg struct {
+	Key string `json:"key"`
+	Id  string `json:"id"`
+}
@@ -0,0 +1,27 @@
+package testflow
+
+import (
+	"testing"
+)
+
+func TestParseCustomOptions(t *testing.T) {
+	data := `{"key":"start","session":"s","suite":{"engines":[{"options":{"hash":16,"threads":1,"custom":{"EvalFile":"nn.nnue","Contempt":24,"Ponder":false}}}]}}`
+	msg, err := NewFlow().Parse("start", []byte(data))
+
+	if err != nil {
+		t.Fatalf("Expected start message to parse, got %v", err)
+	}
+
+	custom := msg.(StartMsg).Suite.Engines[0].Options.Custom
+	expected := map[string]OptionValue{"EvalFile": "nn.nnue", "Contempt": "24", "Ponder": "false"}
+
+	for name, value := range expected {
+		if custom[name] != value {
+			t.Errorf("Expected %s to be %q, got %q", name, value, custom[name])
+		}
+	}
+
+	if _, err := NewFlow().Parse("start", []byte(`{"suite":{"engines":[{"options":{"custom":{"x":[1]}}}]}}`)); err == nil {
+		t.Errorf("Expected an array value to be rejected")
+	}
+}
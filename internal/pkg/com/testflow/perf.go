@@ -0,0 +1,155 @@
+package testflow
+
+import (
+	"math"
+	"sort"
+)
+
+// MoveSample is the performance of an engine on a single move.
+type MoveSample struct {
+	Depth    int // The depth reached.
+	Nps      int // The nodes per second reported by the engine.
+	Time     int // The time used in ms, measured by the adapter.
+	Allotted int // The move time or the share of the clock for the move in ms, or zero if the search was not limited by time.
+}
+
+// PerfStats summarises the performance of an engine over a number of moves.
+// Times are in ms. Moves without a time limit are not counted for the time usage and overshoots.
+type PerfStats struct {
+	Moves         int     `json:"moves"`         // The number of moves.
+	MeanDepth     float64 `json:"meanDepth"`     // The mean depth.
+	MedianDepth   float64 `json:"medianDepth"`   // The median depth.
+	MeanNps       float64 `json:"meanNps"`       // The mean nodes per second.
+	MedianNps     float64 `json:"medianNps"`     // The median nodes per second.
+	MeanTime      float64 `json:"meanTime"`      // The mean time used per move.
+	MedianTime    float64 `json:"medianTime"`    // The median time used per move.
+	TimeUsage     float64 `json:"timeUsage"`     // The time used divided by the time allotted.
+	Overshoots    int     `json:"overshoots"`    // The number of moves which took longer than allotted.
+	OvershootRate float64 `json:"overshootRate"` // The fraction of time limited moves which took longer than allotted.
+}
+
+// PerfTotals accumulates the performance of an engine over any number of moves
+// without keeping the samples. The zero value is empty and ready to use.
+// Medians are taken from histograms of the values, where the nodes per second
+// are rounded to three significant digits.
+type PerfTotals struct {
+	moves      int
+	limited    int
+	used       int
+	allotted   int
+	overshoots int
+	depths     histogram
+	nps        histogram
+	times      histogram
+}
+
+// histogram counts the occurrences of values by a key and sums the values.
+type histogram struct {
+	counts map[int]int
+	sum    float64
+}
+
+// Summarize returns the performance statistics of the samples.
+func Summarize(samples []MoveSample) PerfStats {
+	totals := PerfTotals{}
+	totals.Add(samples...)
+
+	return totals.Stats()
+}
+
+// Add adds the samples to the totals.
+func (t *PerfTotals) Add(samples ...MoveSample) {
+	for _, s := range samples {
+		t.moves++
+		t.depths.add(s.Depth, s.Depth)
+		t.nps.add(s.Nps, roundSignificant(s.Nps, 3))
+		t.times.add(s.Time, s.Time)
+
+		if s.Allotted > 0 {
+			t.limited++
+			t.used += s.Time
+			t.allotted += s.Allotted
+
+			if s.Time > s.Allotted {
+				t.overshoots++
+			}
+		}
+	}
+}
+
+// Stats returns the performance statistics of all added samples.
+func (t PerfTotals) Stats() PerfStats {
+	stats := PerfStats{Moves: t.moves, Overshoots: t.overshoots}
+
+	if t.moves == 0 {
+		return stats
+	}
+
+	n := float64(t.moves)
+	stats.MeanDepth, stats.MedianDepth = t.depths.sum/n, t.depths.median(t.moves)
+	stats.MeanNps, stats.MedianNps = t.nps.sum/n, t.nps.median(t.moves)
+	stats.MeanTime, stats.MedianTime = t.times.sum/n, t.times.median(t.moves)
+
+	if t.allotted > 0 {
+		stats.TimeUsage = float64(t.used) / float64(t.allotted)
+	}
+
+	if t.limited > 0 {
+		stats.OvershootRate = float64(t.overshoots) / float64(t.limited)
+	}
+
+	return stats
+}
+
+// add counts the value under the given key.
+func (h *histogram) add(value int, key int) {
+	if h.counts == nil {
+		h.counts = make(map[int]int)
+	}
+
+	h.counts[key]++
+	h.sum += float64(value)
+}
+
+// median returns the median of the keys of the n counted values.
+func (h histogram) median(n int) float64 {
+	values := make([]int, 0, len(h.counts))
+
+	for v := range h.counts {
+		values = append(values, v)
+	}
+
+	sort.Ints(values)
+
+	// The median is the mean of the values at the zero based positions lo and hi.
+	lo, hi := (n-1)/2, n/2
+	low, seen := 0, 0
+
+	for _, v := range values {
+		next := seen + h.counts[v]
+
+		if seen <= lo && lo < next {
+			low = v
+		}
+
+		if hi < next {
+			return float64(low+v) / 2
+		}
+
+		seen = next
+	}
+
+	return 0
+}
+
+// roundSignificant rounds the value to the given number of significant digits.
+func roundSignificant(value int, digits int) int {
+	limit := int(math.Pow10(digits))
+	scale := 1
+
+	for value/scale >= limit {
+		scale *= 10
+	}
+
+	return (value + scale/2) / scale * scale
+}
@@ -0,0 +1,52 @@
+package testflow
+
+import (
+	"testing"
+)
+
+type perf_io struct {
+	samples []MoveSample
+	out     PerfStats
+}
+
+var perfs = []perf_io{
+	{[]MoveSample{}, PerfStats{}},
+	{
+		[]MoveSample{{Depth: 10, Nps: 1000, Time: 100, Allotted: 100}, {Depth: 12, Nps: 3000, Time: 150, Allotted: 100}, {Depth: 20, Nps: 2000, Time: 50, Allotted: 100}},
+		PerfStats{Moves: 3, MeanDepth: 14, MedianDepth: 12, MeanNps: 2000, MedianNps: 2000, MeanTime: 100, MedianTime: 100, TimeUsage: 1, Overshoots: 1, OvershootRate: 1.0 / 3},
+	},
+	{
+		[]MoveSample{{Depth: 8, Nps: 10, Time: 40}, {Depth: 9, Nps: 20, Time: 60, Allotted: 120}},
+		PerfStats{Moves: 2, MeanDepth: 8.5, MedianDepth: 8.5, MeanNps: 15, MedianNps: 15, MeanTime: 50, MedianTime: 50, TimeUsage: 0.5},
+	},
+}
+
+func TestSummarize(t *testing.T) {
+	for _, io := range perfs {
+		if out := Summarize(io.samples); out != io.out {
+			t.Errorf("Expected %+v, got %+v", io.out, out)
+		}
+	}
+}
+
+func TestPerfTotals(t *testing.T) {
+	samples := []MoveSample{
+		{Depth: 0, Nps: 123456, Time: 0, Allotted: 10},
+		{Depth: 7, Nps: 123549, Time: 20, Allotted: 10},
+		{Depth: 7, Nps: 999, Time: 5},
+		{Depth: 9, Nps: 1000, Time: 30, Allotted: 40},
+		{Depth: 3, Nps: 2000, Time: 10},
+	}
+	totals := PerfTotals{}
+
+	for _, s := range samples {
+		totals.Add(s)
+	}
+
+	out := totals.Stats()
+	expected := PerfStats{Moves: 5, MeanDepth: 5.2, MedianDepth: 7, MeanNps: 50200.8, MedianNps: 2000, MeanTime: 13, MedianTime: 10, TimeUsage: 50.0 / 60, Overshoots: 1, OvershootRate: 1.0 / 3}
+
+	if out != expected {
+		t.Errorf("Expected %+v, got %+v", expected, out)
+	}
+}
@@ -0,0 +1,245 @@
+package testflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+)
+
+// Verbosity controls which moves and logs of the games are reported.
+type Verbosity string
+
+const (
+	VerbosityNone   Verbosity = "none"   // Only the results are reported.
+	VerbosityMoves  Verbosity = "moves"  // The results and moves are reported.
+	VerbosityErrors Verbosity = "errors" // The results and moves are reported, logs only for failed games.
+	VerbosityFull   Verbosity = "full"   // The results, moves and logs of all games are reported.
+)
+
+// Encoding is the encoding of the moves and logs in a report.
+const Encoding = "gzip+base64"
+
+// ParseVerbosity returns the verbosity with the given name.
+func ParseVerbosity(name string) (Verbosity, bool) {
+	switch v := Verbosity(name); v {
+	case VerbosityNone, VerbosityMoves, VerbosityErrors, VerbosityFull:
+		return v, true
+	default:
+		return VerbosityFull, false
+	}
+}
+
+// BuildReportCmds returns the ReportCmds for a batch of games.
+// The moves and logs are filtered according to the verbosity and compressed.
+// If the encoded report exceeds maxSize bytes, the games are split into several chunks,
+// each with an id derived from the given one. A maxSize of zero disables chunking.
+// A single game which exceeds maxSize is sent as its own chunk.
+// The performance statistics of both engines in the batch and the reason why the
+// batch was aborted, if it was, are added to every chunk.
+func BuildReportCmds(id string, session string, moves []GameMoveHistory, logs []Log, results []GameResult, perf [2]PerfStats, aborted string, verbosity Verbosity, maxSize int) ([]ReportCmd, error) {
+	moves, logs = filterReport(moves, logs, results, verbosity)
+	build := func(g [2]int) (ReportCmd, error) {
+		m, err := compress(moves[g[0]:g[1]])
+
+		if err != nil {
+			return ReportCmd{}, err
+		}
+
+		l, err := compress(logs[g[0]:g[1]])
+
+		if err != nil {
+			return ReportCmd{}, err
+		}
+
+		return ReportCmd{
+			Key:      "report",
+			Id:       id,
+			Session:  session,
+			Encoding: Encoding,
+			Moves:    m,
+			Logs:     l,
+			Results:  results[g[0]:g[1]],
+			Perf:     perf,
+			Aborted:  aborted,
+		}, nil
+	}
+	groups := [][2]int{{0, len(results)}}
+
+	if maxSize > 0 {
+		empty, err := build([2]int{0, 0})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if groups, err = splitReport(moves, logs, results, maxSize, encodedSize(empty, len(results))); err != nil {
+			return nil, err
+		}
+	}
+
+	cmds := make([]ReportCmd, 0, len(groups))
+
+	// The groups are estimated, so a group which still exceeds maxSize is split in halves.
+	for len(groups) > 0 {
+		g := groups[0]
+		groups = groups[1:]
+		cmd, err := build(g)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if maxSize > 0 && g[1]-g[0] > 1 && encodedSize(cmd, len(results)) > maxSize {
+			mid := (g[0] + g[1]) / 2
+			groups = append([][2]int{{g[0], mid}, {mid, g[1]}}, groups...)
+			continue
+		}
+
+		cmds = append(cmds, cmd)
+	}
+
+	for idx := range cmds {
+		cmds[idx].Chunk = idx
+		cmds[idx].Chunks = len(cmds)
+
+		if len(cmds) > 1 {
+			cmds[idx].Id = id + "-" + strconv.Itoa(idx)
+		}
+	}
+
+	return cmds, nil
+}
+
+// encodedSize returns the size of the encoded chunk of a report with at most n chunks.
+// The chunk index and the id suffix are assumed to have the most digits possible.
+func encodedSize(cmd ReportCmd, n int) int {
+	cmd.Id += "-" + strconv.Itoa(n)
+	cmd.Chunk = n
+	cmd.Chunks = n
+
+	return len(cmd.Encode())
+}
+
+// BuildGameReportCmd returns a GameReportCmd for a single game with the given parameters.
+// The moves and logs are filtered according to the verbosity and compressed.
+// The id is sent back by the server in an AckMsg once the report was stored.
+func BuildGameReportCmd(id string, session string, moves GameMoveHistory, logs [][]LogEntry, result GameResult, verbosity Verbosity) (GameReportCmd, error) {
+	filteredMoves, filteredLogs := filterReport([]GameMoveHistory{moves}, []Log{logs}, []GameResult{result}, verbosity)
+	m, err := compress(filteredMoves[0])
+
+	if err != nil {
+		return GameReportCmd{}, err
+	}
+
+	l, err := compress(filteredLogs[0])
+
+	if err != nil {
+		return GameReportCmd{}, err
+	}
+
+	return GameReportCmd{
+		Key:      "game-report",
+		Id:       id,
+		Session:  session,
+		Encoding: Encoding,
+		Moves:    m,
+		Logs:     l,
+		Result:   result,
+	}, nil
+}
+
+// Decompress decodes the moves or logs of a report into v.
+func Decompress(data string, v any) error {
+	buf, err := base64.StdEncoding.DecodeString(data)
+
+	if err != nil {
+		return err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(buf))
+
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(v)
+}
+
+// filterReport removes the moves and logs which are not reported with the verbosity.
+// The returned slices have the same length as the results, with nil for omitted games.
+func filterReport(moves []GameMoveHistory, logs []Log, results []GameResult, verbosity Verbosity) ([]GameMoveHistory, []Log) {
+	filteredMoves := make([]GameMoveHistory, len(results))
+	filteredLogs := make([]Log, len(results))
+
+	for idx, res := range results {
+		if verbosity != VerbosityNone && idx < len(moves) {
+			filteredMoves[idx] = moves[idx]
+		}
+
+		if idx < len(logs) && (verbosity == VerbosityFull || (verbosity == VerbosityErrors && res.Failure != nil)) {
+			filteredLogs[idx] = logs[idx]
+		}
+	}
+
+	return filteredMoves, filteredLogs
+}
+
+// splitReport groups consecutive games, so the encoded report of each group stays
+// below maxSize bytes. The size of a group is estimated from the size of the report
+// without games and the compressed moves and logs and the encoded result of its games.
+func splitReport(moves []GameMoveHistory, logs []Log, results []GameResult, maxSize int, base int) ([][2]int, error) {
+	groups := make([][2]int, 0)
+	start := 0
+	size := base
+
+	for idx := range results {
+		m, err := compress(moves[idx])
+
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := compress(logs[idx])
+
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := json.Marshal(results[idx])
+
+		if err != nil {
+			return nil, err
+		}
+
+		gameSize := len(m) + len(l) + len(r) + 1
+
+		if idx > start && size+gameSize > maxSize {
+			groups = append(groups, [2]int{start, idx})
+			start = idx
+			size = base
+		}
+
+		size += gameSize
+	}
+
+	return append(groups, [2]int{start, len(results)}), nil
+}
+
+// compress encodes v as JSON, compresses it with gzip and encodes the result with base64.
+func compress(v any) (string, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+
+	if err := json.NewEncoder(writer).Encode(v); err != nil {
+		return "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
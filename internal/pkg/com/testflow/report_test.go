@@ -0,0 +1,145 @@
+package testflow
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type verbosity_io struct {
+	verbosity Verbosity
+	moves     int
+	logs      int
+}
+
+var verbosities = []verbosity_io{
+	{VerbosityNone, 0, 0},
+	{VerbosityMoves, 3, 0},
+	{VerbosityErrors, 3, 1},
+	{VerbosityFull, 3, 3},
+}
+
+func testGames(n int, logSize int) ([]GameMoveHistory, []Log, []GameResult) {
+	moves := make([]GameMoveHistory, n)
+	logs := make([]Log, n)
+	results := make([]GameResult, n)
+
+	for i := 0; i < n; i++ {
+		moves[i] = GameMoveHistory{{{Move: "e2e4", Depth: 10}}, {{Move: "e7e5", Depth: 9}}}
+		logs[i] = Log{{{Type: "recv", Value: strings.Repeat("info depth 1 ", logSize)}}, {}}
+		results[i] = GameResult{White: i % 2, Outcome: Draw, Reason: "repetition"}
+	}
+
+	return moves, logs, results
+}
+
+func TestReportVerbosity(t *testing.T) {
+	moves, logs, results := testGames(3, 1)
+	results[1].Failure = &Failure{Kind: CrashFailure}
+
+	for _, io := range verbosities {
+		cmds, err := BuildReportCmds("id", "session", moves, logs, results, [2]PerfStats{}, "", io.verbosity, 0)
+
+		if err != nil || len(cmds) != 1 {
+			t.Fatalf("Expected a single report, got %d (%v)", len(cmds), err)
+		}
+
+		var m []GameMoveHistory
+		var l []Log
+
+		if err := Decompress(cmds[0].Moves, &m); err != nil {
+			t.Fatalf("Expected moves to decompress, got %v", err)
+		}
+
+		if err := Decompress(cmds[0].Logs, &l); err != nil {
+			t.Fatalf("Expected logs to decompress, got %v", err)
+		}
+
+		count := func(n int, isSet func(int) bool) int {
+			res := 0
+
+			for i := 0; i < n; i++ {
+				if isSet(i) {
+					res++
+				}
+			}
+
+			return res
+		}
+
+		if c := count(len(m), func(i int) bool { return m[i] != nil }); c != io.moves {
+			t.Errorf("Expected %d move histories for %s, got %d", io.moves, io.verbosity, c)
+		}
+
+		if c := count(len(l), func(i int) bool { return l[i] != nil }); c != io.logs {
+			t.Errorf("Expected %d logs for %s, got %d", io.logs, io.verbosity, c)
+		}
+	}
+}
+
+func TestReportChunks(t *testing.T) {
+	moves, logs, results := testGames(10, 2000)
+	single, _ := BuildReportCmds("id", "session", moves[:1], logs[:1], results[:1], [2]PerfStats{}, "", VerbosityFull, 0)
+	size := len(single[0].Moves) + len(single[0].Logs)
+	cmds, err := BuildReportCmds("id", "session", moves, logs, results, [2]PerfStats{}, "", VerbosityFull, size*3)
+
+	if err != nil {
+		t.Fatalf("Expected report to build, got %v", err)
+	}
+
+	if len(cmds) < 4 {
+		t.Fatalf("Expected at least 4 chunks, got %d", len(cmds))
+	}
+
+	games := 0
+
+	for idx, cmd := range cmds {
+		var m []GameMoveHistory
+
+		if cmd.Chunk != idx || cmd.Chunks != len(cmds) || cmd.Id != "id-"+strconv.Itoa(idx) {
+			t.Errorf("Expected chunk %d of %d, got %d of %d with id %s", idx, len(cmds), cmd.Chunk, cmd.Chunks, cmd.Id)
+		}
+
+		Decompress(cmd.Moves, &m)
+
+		if len(m) != len(cmd.Results) || m[0][0][0].Move != "e2e4" {
+			t.Errorf("Expected moves to match the results of chunk %d", idx)
+		}
+
+		games += len(cmd.Results)
+	}
+
+	if games != 10 {
+		t.Errorf("Expected 10 games in all chunks, got %d", games)
+	}
+}
+
+func TestReportChunkSize(t *testing.T) {
+	moves, logs, results := testGames(500, 1)
+
+	for i := range results {
+		results[i].Failure = &Failure{Kind: CrashFailure, Message: strings.Repeat("crashed ", 10)}
+	}
+
+	perf := [2]PerfStats{{Moves: 100, MeanDepth: 12.5}, {Moves: 100, MeanDepth: 11.5}}
+	maxSize := 4000
+	cmds, err := BuildReportCmds("id", "session", moves, logs, results, perf, "Aborted games: too many failures", VerbosityFull, maxSize)
+
+	if err != nil {
+		t.Fatalf("Expected report to build, got %v", err)
+	}
+
+	games := 0
+
+	for _, cmd := range cmds {
+		if size := len(cmd.Encode()); size > maxSize {
+			t.Errorf("Expected chunk %d to be at most %d bytes, got %d", cmd.Chunk, maxSize, size)
+		}
+
+		games += len(cmd.Results)
+	}
+
+	if games != len(results) {
+		t.Errorf("Expected %d games in all chunks, got %d", len(results), games)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -30,6 +31,62 @@ type ServerConfig struct {
 	Secure bool   // Whether the server uses HTTPS / WSS.
 }
 
+// SPRTConfig contains the bounds of the sequential probability ratio test
+// which is run on the results of the test games.
+// The configuration has to have the following structure:
+//
+//	sprt:
+//		elo0: <float>
+//		elo1: <float>
+//		alpha: <float>
+//		beta: <float>
+type SPRTConfig struct {
+	Elo0  float64 // The Elo difference of the null hypothesis.
+	Elo1  float64 // The Elo difference of the alternative hypothesis.
+	Alpha float64 // The probability of a false positive.
+	Beta  float64 // The probability of a false negative.
+}
+
+// ResourceConfig limits the resources the test worker uses for its games.
+// Zero values do not limit the resource. The configuration has to have the following structure:
+//
+//	resources:
+//		concurrency: <int>
+//		max-cores: <int>
+//		max-memory: <int>
+//		reserve-cores: <int>
+type ResourceConfig struct {
+	Concurrency  int // The fixed number of concurrent games. Replaces the calculated number if set.
+	MaxCores     int // The maximum number of cores used by the games.
+	MaxMemory    int // The maximum memory used by the games in MB.
+	ReserveCores int // The number of cores which are kept free for other programs.
+}
+
+// ReportConfig controls the reports of the test worker.
+// The configuration has to have the following structure:
+//
+//	report:
+//		verbosity: <none|moves|errors|full>
+//		max-size: <int>
+type ReportConfig struct {
+	Verbosity string // Which moves and logs are reported.
+	MaxSize   int    // The size in bytes at which a report is split into chunks. Zero disables chunking.
+}
+
+// ReconnectConfig controls how the connection to the game server or the test server is restored after it dropped.
+// The delay between two attempts starts at the initial delay and doubles after each
+// failed attempt up to the maximum delay. The configuration has to have the following structure:
+//
+//	reconnect:
+//		attempts: <int>
+//		initial-delay: <int>
+//		max-delay: <int>
+type ReconnectConfig struct {
+	Attempts     int           // The number of attempts before the connection is given up. Zero disables reconnecting.
+	InitialDelay time.Duration // The delay before the first attempt, configured in ms.
+	MaxDelay     time.Duration // The maximum delay between two attempts, configured in ms.
+}
+
 // Configurations for the different servers and storage options.
 var (
 	evc         ServerConfig // Configuration of the server which provides the engine version control.
@@ -37,6 +94,20 @@ var (
 	gameManager ServerConfig // Configuration of the server which provides the game manager.
 	test        ServerConfig // Configuration of the server which provides the test server.
 	engineStore string       // The path to the directory where the engines are stored.
+	outbox      string       // The path to the directory where unacknowledged reports are stored.
+)
+
+// Options that control how games are played.
+var (
+	timeOverhead time.Duration   // The time an engine may exceed its clock before losing on time.
+	latency      time.Duration   // The network latency subtracted from the time of the engine in play mode.
+	restarts     int             // How often a crashed engine is restarted during a game in play mode.
+	sprt         SPRTConfig      // The bounds of the SPRT shown for test games.
+	failureRate  float64         // The share of failed games at which a batch is aborted.
+	resources    ResourceConfig  // The resource limits of the test worker.
+	cpuAffinity  bool            // Whether engines of concurrent games are pinned to disjoint CPUs.
+	report       ReportConfig    // The configuration of the reports of the test worker.
+	reconnect    ReconnectConfig // How the connection to a server is restored.
 )
 
 // Load loads the configuration from the file ivyconf.yaml in the
@@ -67,21 +138,63 @@ func Load(path string) {
 	initServerConfig(&gameManager, "game-manager", "localhost", 4501, false)
 	initServerConfig(&test, "test", "localhost", 4504, false)
 
+	viper.SetDefault("time-overhead", 50)
+	viper.SetDefault("latency-overhead", 100)
+	viper.SetDefault("engine-restarts", 3)
+	viper.SetDefault("max-failure-rate", 0.5)
+	viper.SetDefault("cpu-affinity", true)
+	viper.SetDefault("report.verbosity", "full")
+	viper.SetDefault("report.max-size", 1<<20)
+	viper.SetDefault("sprt.elo0", 0.0)
+	viper.SetDefault("sprt.elo1", 5.0)
+	viper.SetDefault("sprt.alpha", 0.05)
+	viper.SetDefault("sprt.beta", 0.05)
+	viper.SetDefault("reconnect.attempts", 10)
+	viper.SetDefault("reconnect.initial-delay", 500)
+	viper.SetDefault("reconnect.max-delay", 30000)
+
+	timeOverhead = time.Duration(viper.GetInt("time-overhead")) * time.Millisecond
+	latency = time.Duration(viper.GetInt("latency-overhead")) * time.Millisecond
+	restarts = viper.GetInt("engine-restarts")
+	failureRate = viper.GetFloat64("max-failure-rate")
+	cpuAffinity = viper.GetBool("cpu-affinity")
+	report.Verbosity = viper.GetString("report.verbosity")
+	report.MaxSize = viper.GetInt("report.max-size")
+	resources.Concurrency = viper.GetInt("resources.concurrency")
+	resources.MaxCores = viper.GetInt("resources.max-cores")
+	resources.MaxMemory = viper.GetInt("resources.max-memory")
+	resources.ReserveCores = viper.GetInt("resources.reserve-cores")
+	sprt.Elo0 = viper.GetFloat64("sprt.elo0")
+	sprt.Elo1 = viper.GetFloat64("sprt.elo1")
+	sprt.Alpha = viper.GetFloat64("sprt.alpha")
+	sprt.Beta = viper.GetFloat64("sprt.beta")
+	reconnect.Attempts = viper.GetInt("reconnect.attempts")
+	reconnect.InitialDelay = time.Duration(viper.GetInt("reconnect.initial-delay")) * time.Millisecond
+	reconnect.MaxDelay = time.Duration(viper.GetInt("reconnect.max-delay")) * time.Millisecond
 	engineStore = viper.GetString("engine-store")
 
 	engineStore, _ = filepath.Abs(engineStore)
 
 	if engineStore == "" {
-		home := os.Getenv("HOME")
-		ivy := os.Getenv("IVY_PATH")
-		base := home + "/Ivy"
+		engineStore = ivyDir() + "/engines"
+	}
 
-		if ivy != "" {
-			base = ivy + "/Ivy"
-		}
+	outbox = viper.GetString("outbox")
 
-		engineStore = base + "/engines"
+	if outbox == "" {
+		outbox = ivyDir() + "/outbox"
 	}
+
+	outbox, _ = filepath.Abs(outbox)
+}
+
+// ivyDir returns the Ivy directory, which is located in $IVY_PATH or $HOME.
+func ivyDir() string {
+	if ivy := os.Getenv("IVY_PATH"); ivy != "" {
+		return ivy + "/Ivy"
+	}
+
+	return os.Getenv("HOME") + "/Ivy"
 }
 
 // GetTestServerConfig returns the configuration of the server which provides the test server.
@@ -94,6 +207,65 @@ func GetEngineStore() string {
 	return engineStore
 }
 
+// GetOutbox returns the path to the directory where reports are stored until the
+// server acknowledged them. The path is configured using the key "outbox".
+func GetOutbox() string {
+	return outbox
+}
+
+// GetTimeOverhead returns the time an engine may exceed its clock before the flag falls.
+// The overhead is configured in milliseconds using the key "time-overhead".
+func GetTimeOverhead() time.Duration {
+	return timeOverhead
+}
+
+// GetLatencyOverhead returns the time which is subtracted from the clock of an engine
+// playing on the game server to compensate for the network latency of a move.
+// The overhead is configured in milliseconds using the key "latency-overhead".
+func GetLatencyOverhead() time.Duration {
+	return latency
+}
+
+// GetEngineRestarts returns how often an engine playing on the game server is restarted
+// during a game after it crashed or stopped responding.
+// The limit is configured using the key "engine-restarts".
+func GetEngineRestarts() int {
+	return restarts
+}
+
+// GetMaxFailureRate returns the share of failed games, between 0 and 1, at which
+// a batch of test games is aborted. The rate is configured using the key "max-failure-rate".
+func GetMaxFailureRate() float64 {
+	return failureRate
+}
+
+// GetCPUAffinity returns whether the engines of concurrent games are pinned to disjoint sets of CPUs.
+// Pinning is only supported on Linux and configured using the key "cpu-affinity".
+func GetCPUAffinity() bool {
+	return cpuAffinity
+}
+
+// GetReportConfig returns the configuration of the reports of the test worker.
+func GetReportConfig() *ReportConfig {
+	return &report
+}
+
+// GetReconnectConfig returns how the connection to the game server or the test server is restored after it dropped.
+func GetReconnectConfig() *ReconnectConfig {
+	return &reconnect
+}
+
+// GetResourceConfig returns the resource limits of the test worker.
+// The returned configuration can be modified to override the configured limits.
+func GetResourceConfig() *ResourceConfig {
+	return &resources
+}
+
+// GetSPRTConfig returns the bounds of the sequential probability ratio test.
+func GetSPRTConfig() *SPRTConfig {
+	return &sprt
+}
+
 // GetEVCConfig returns the configuration of the server which provides the engine version control.
 func GetEVCConfig() *ServerConfig {
 	return &evc
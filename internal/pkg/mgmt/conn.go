@@ -1,18 +1,34 @@
 package mgmt
 
+import (
+	"sync"
+	"time"
+)
+
 // Connection is a wrapper for the communication between the adapter and the
 // engine. It provides a simple interface to send commands to the engine and
 // receive its responses.
 type Connection struct {
-	in  chan string
-	out chan string
-	Pid int
+	in     chan string
+	out    chan string
+	exited chan struct{}
+	done   chan struct{} // Closed when the connection is closed, so nobody reads or writes any more.
+	once   *sync.Once
+	Pid    int
 }
 
 // NewConnection creates a new connection between the adapter and the engine.
 // It returns a pointer to the connection.
 func NewConnection(pid int, in chan string, out chan string) *Connection {
-	return &Connection{in, out, pid}
+	return &Connection{in, out, make(chan struct{}), make(chan struct{}), &sync.Once{}, pid}
+}
+
+// Close stops the communication with the engine. Commands sent afterwards are
+// dropped and the remaining output of the engine is discarded.
+func (conn *Connection) Close() {
+	conn.once.Do(func() {
+		close(conn.done)
+	})
 }
 
 // Expect sends a command to the engine and waits for a confirmation. If the
@@ -21,7 +37,7 @@ func NewConnection(pid int, in chan string, out chan string) *Connection {
 func (conn *Connection) Expect(cmd string, cnf string) []string {
 	var result []string
 
-	conn.in <- cmd
+	conn.Send(cmd)
 
 	for resp := range conn.out {
 		if resp == cnf {
@@ -34,9 +50,12 @@ func (conn *Connection) Expect(cmd string, cnf string) []string {
 	return []string{}
 }
 
-// Send sends a command to the engine.
+// Send sends a command to the engine. The command is dropped if the connection is closed.
 func (conn *Connection) Send(cmd string) {
-	conn.in <- cmd
+	select {
+	case conn.in <- cmd:
+	case <-conn.done:
+	}
 }
 
 // Line sends a command to the engine and waits for a response. The response is
@@ -49,7 +68,7 @@ func (conn *Connection) Line() string {
 // returned as a string. The response is passed to the filter function. If the
 // filter function returns true, the function returns.
 func (conn *Connection) Scan(cmd string, filter func(resp string) bool) {
-	conn.in <- cmd
+	conn.Send(cmd)
 	conn.Read(filter)
 }
 
@@ -63,6 +82,44 @@ func (conn *Connection) Read(filter func(resp string) bool) {
 	}
 }
 
+// ReadTimeout reads the responses from the engine until the filter function
+// returns true. It returns false if the engine did not respond within the
+// timeout or the engine process exited before. A timeout of zero waits forever.
+func (conn *Connection) ReadTimeout(filter func(resp string) bool, timeout time.Duration) bool {
+	var expired <-chan time.Time
+
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		expired = timer.C
+		defer timer.Stop()
+	}
+
+	for {
+		select {
+		case resp, ok := <-conn.out:
+			if !ok {
+				return false
+			}
+
+			if filter(resp) {
+				return true
+			}
+		case <-expired:
+			return false
+		}
+	}
+}
+
+// Exited returns true if the engine closed its output, which happens when the process exits.
+func (conn *Connection) Exited() bool {
+	select {
+	case <-conn.exited:
+		return true
+	default:
+		return false
+	}
+}
+
 // Next returns the next response from the engine.
 func (conn *Connection) Next() string {
 	return <-conn.out
@@ -104,6 +104,34 @@ func (inst EngineInstance) IsInstalled() bool {
 	return err == nil
 }
 
+// FindInstalled returns an installed instance of the engine with the given name and version.
+// Only the engine store is searched, so no connection to the EVC server is required.
+// If several flavours of the engine are installed, the first one found is returned.
+func FindInstalled(name string, version Version) (*EngineInstance, error) {
+	prefix := name + "_" + version.String(UrlSaveVersionStyle) + "_"
+	entries, err := os.ReadDir(conf.GetEngineStore())
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		file := strings.TrimSuffix(entry.Name(), ".exe")
+
+		if entry.IsDir() || !strings.HasPrefix(file, prefix) || strings.HasSuffix(file, ".tmp") {
+			continue
+		}
+
+		return &EngineInstance{
+			Engine:  name,
+			Version: version,
+			Id:      strings.TrimPrefix(file, prefix),
+		}, nil
+	}
+
+	return nil, errors.New("Engine is not installed: " + name + " " + version.String(DotVersionStyle))
+}
+
 // URL returns the URL to the engine instance on the EVC server.
 func (inst EngineInstance) URL() string {
 	return conf.GetEVCConfig().GetURL() + "/engines/bin/" + inst.Engine + "/" + inst.Id
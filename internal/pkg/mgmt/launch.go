@@ -2,9 +2,12 @@ package mgmt
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
+
+	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/sys"
 )
 
 // Launch launches the engine instance and returns a connection to it.
@@ -19,6 +22,12 @@ func (e *EngineInstance) Launch(scb func(string), rcb func(string)) (*Connection
 // The connection is used to communicate with the engine using channels.
 // If the engine instance could not be launched, an error is returned and the connection is nil.
 func LaunchEngine(path string, scb func(string), rcb func(string)) (*Connection, error) {
+	return LaunchPinnedEngine(path, nil, scb, rcb)
+}
+
+// LaunchPinnedEngine is equal to LaunchEngine but pins the engine process to the given CPUs.
+// Pinning is skipped if no CPUs are given. If the engine could not be pinned, it is killed and an error is returned.
+func LaunchPinnedEngine(path string, cpus []int, scb func(string), rcb func(string)) (*Connection, error) {
 	proc := exec.Command(path)
 
 	inPipe, _ := proc.StdinPipe()
@@ -33,32 +42,56 @@ func LaunchEngine(path string, scb func(string), rcb func(string)) (*Connection,
 		return nil, e
 	}
 
-	return bind(proc.Process.Pid, in, out, inPipe, outPipe, scb, rcb), nil
-}
+	if len(cpus) > 0 {
+		if err := sys.SetAffinity(proc.Process.Pid, cpus); err != nil {
+			proc.Process.Kill()
+			proc.Wait()
+			return nil, errors.New("Could not pin engine to CPUs: " + err.Error())
+		}
+	}
+
+	conn := NewConnection(proc.Process.Pid, in, out)
 
-func bind(pid int, in chan string, out chan string, wr io.Writer, rd io.Reader, scb func(string), rcb func(string)) *Connection {
-	go distribute(in, wr, scb)
-	go listen(rd, out, rcb)
+	go distribute(in, inPipe, conn.done, scb)
+	go func() {
+		listen(outPipe, out, conn.done, rcb)
+		proc.Wait()
+		close(conn.exited)
+		close(out)
+	}()
 
-	return &Connection{in, out, pid}
+	return conn, nil
 }
 
-func distribute(in chan string, wr io.Writer, cb func(string)) {
-	for cmd := range in {
-		wr.Write([]byte(cmd + "\n"))
+// distribute writes the commands to the engine until the connection is closed.
+func distribute(in chan string, wr io.Writer, done chan struct{}, cb func(string)) {
+	for {
+		select {
+		case cmd := <-in:
+			wr.Write([]byte(cmd + "\n"))
 
-		if cb != nil {
-			cb(cmd)
+			if cb != nil {
+				cb(cmd)
+			}
+		case <-done:
+			return
 		}
 	}
 }
 
-func listen(rd io.Reader, out chan string, cb func(string)) {
+// listen forwards the output of the engine until the engine closed its output.
+// After the connection was closed, the output is read but no longer forwarded,
+// so the engine never blocks on a full pipe.
+func listen(rd io.Reader, out chan string, done chan struct{}, cb func(string)) {
 	scanner := bufio.NewScanner(rd)
 
 	for scanner.Scan() {
 		text := scanner.Text()
-		out <- text
+
+		select {
+		case out <- text:
+		case <-done:
+		}
 
 		if cb != nil {
 			cb(text)
@@ -0,0 +1,249 @@
+// Package spsa implements simultaneous perturbation stochastic approximation
+// (SPSA) for tuning integer engine parameters by self-play.
+// Each iteration perturbs all parameters at once in a random direction, lets an
+// engine with the positively perturbed parameters play a game pair against an
+// engine with the negatively perturbed parameters and moves the parameters
+// towards the side which scored better.
+package spsa
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Gain sequence exponents and stability constant as recommended by Spall.
+const (
+	alpha     = 0.602
+	gamma     = 0.101
+	stability = 0.1
+)
+
+// DefaultRate is the learning rate at the end of the tuning, if a parameter does not define one.
+const DefaultRate = 0.002
+
+// Param is a tuned engine option with its current value and range.
+type Param struct {
+	Name  string  `json:"name"`  // The name of the UCI option.
+	Value float64 `json:"value"` // The current value, which is rounded when sent to the engine.
+	Min   int     `json:"min"`   // The smallest allowed value.
+	Max   int     `json:"max"`   // The largest allowed value.
+	Step  float64 `json:"step"`  // The perturbation at the end of the tuning.
+	Rate  float64 `json:"rate"`  // The learning rate at the end of the tuning.
+}
+
+// Tuner holds the state of a tuning run and is saved as checkpoint.
+type Tuner struct {
+	Params     []Param `json:"params"`
+	Iteration  int     `json:"iteration"`  // The number of finished iterations.
+	Iterations int     `json:"iterations"` // The total number of iterations.
+}
+
+// Perturbation is the pair of parameter sets which play against each other in an iteration.
+type Perturbation struct {
+	Iteration int       // The iteration the perturbation was created for.
+	Delta     []float64 // The random direction, +1 or -1 per parameter.
+	Step      []float64 // The perturbation size per parameter.
+	Plus      []int     // The values of the positively perturbed engine.
+	Minus     []int     // The values of the negatively perturbed engine.
+}
+
+// ParseParams reads a parameter list with one parameter per line in the format
+// "name value min max step [rate]". Fields are separated by whitespace or commas.
+// Empty lines and lines starting with '#' are ignored.
+func ParseParams(r io.Reader) ([]Param, error) {
+	params := make([]Param, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t'
+		})
+
+		if len(fields) < 5 || len(fields) > 6 {
+			return nil, errors.New("Invalid parameter: " + line)
+		}
+
+		p := Param{Name: fields[0], Rate: DefaultRate}
+		value, err1 := strconv.ParseFloat(fields[1], 64)
+		min, err2 := strconv.Atoi(fields[2])
+		max, err3 := strconv.Atoi(fields[3])
+		step, err4 := strconv.ParseFloat(fields[4], 64)
+
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			return nil, errors.New("Invalid parameter: " + line)
+		}
+
+		if len(fields) == 6 {
+			rate, err := strconv.ParseFloat(fields[5], 64)
+
+			if err != nil || rate <= 0 {
+				return nil, errors.New("Invalid learning rate: " + line)
+			}
+
+			p.Rate = rate
+		}
+
+		if min > max || value < float64(min) || value > float64(max) {
+			return nil, errors.New("Value of parameter is not within its range: " + line)
+		}
+
+		if step <= 0 {
+			return nil, errors.New("Step of parameter has to be positive: " + line)
+		}
+
+		p.Value, p.Min, p.Max, p.Step = value, min, max, step
+		params = append(params, p)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(params) == 0 {
+		return nil, errors.New("No parameters to tune")
+	}
+
+	return params, nil
+}
+
+// New returns a tuner which runs the given number of iterations.
+func New(params []Param, iterations int) *Tuner {
+	return &Tuner{
+		Params:     params,
+		Iterations: iterations,
+	}
+}
+
+// Load reads a tuner from a checkpoint.
+func Load(path string) (*Tuner, error) {
+	buf, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tuner{}
+
+	if err := json.Unmarshal(buf, t); err != nil {
+		return nil, errors.New("Invalid checkpoint: " + path)
+	}
+
+	return t, nil
+}
+
+// Save writes the tuner to a checkpoint.
+// The checkpoint is written to a temporary file first, so an interrupted save keeps the previous checkpoint.
+func (t *Tuner) Save(path string) error {
+	buf, err := json.MarshalIndent(t, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path+".tmp", buf, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// Done returns true if all iterations were run.
+func (t *Tuner) Done() bool {
+	return t.Iteration >= t.Iterations
+}
+
+// Values returns the current values of the parameters rounded to integers.
+func (t *Tuner) Values() []int {
+	values := make([]int, len(t.Params))
+
+	for idx, p := range t.Params {
+		values[idx] = p.clamp(p.Value)
+	}
+
+	return values
+}
+
+// Perturb returns a random perturbation of the current parameters for the given iteration.
+func (t *Tuner) Perturb(iteration int, rng *rand.Rand) Perturbation {
+	p := Perturbation{
+		Iteration: iteration,
+		Delta:     make([]float64, len(t.Params)),
+		Step:      make([]float64, len(t.Params)),
+		Plus:      make([]int, len(t.Params)),
+		Minus:     make([]int, len(t.Params)),
+	}
+
+	for idx, param := range t.Params {
+		p.Delta[idx] = 1
+
+		if rng.Intn(2) == 0 {
+			p.Delta[idx] = -1
+		}
+
+		p.Step[idx] = t.step(param, iteration)
+		p.Plus[idx] = param.clamp(param.Value + p.Step[idx]*p.Delta[idx])
+		p.Minus[idx] = param.clamp(param.Value - p.Step[idx]*p.Delta[idx])
+	}
+
+	return p
+}
+
+// Update moves the parameters according to the result of a perturbation and finishes an iteration.
+// The result is the number of wins minus the number of losses of the positively
+// perturbed engine, e.g. between -2 and 2 for a game pair.
+func (t *Tuner) Update(p Perturbation, result float64) {
+	for idx := range t.Params {
+		param := &t.Params[idx]
+		gain := t.gain(*param, p.Iteration) / p.Step[idx]
+		value := param.Value + gain*result*p.Delta[idx]
+		param.Value = math.Max(float64(param.Min), math.Min(float64(param.Max), value))
+	}
+
+	t.Iteration++
+}
+
+// step returns the perturbation size c_k of the parameter in the given iteration.
+// It decreases over time, so it equals the step of the parameter in the last iteration.
+func (t *Tuner) step(p Param, iteration int) float64 {
+	n := math.Max(float64(t.Iterations), 1)
+	return p.Step * math.Pow(n, gamma) / math.Pow(float64(iteration+1), gamma)
+}
+
+// gain returns the gain a_k of the parameter in the given iteration.
+// The update of a parameter is a_k / c_k * result, and a_k equals rate * step²
+// in the last iteration.
+func (t *Tuner) gain(p Param, iteration int) float64 {
+	n := math.Max(float64(t.Iterations), 1)
+	a := stability * n
+	end := p.Rate * p.Step * p.Step
+
+	return end * math.Pow(a+n, alpha) / math.Pow(a+float64(iteration+1), alpha)
+}
+
+// clamp rounds the value and restricts it to the range of the parameter.
+func (p Param) clamp(value float64) int {
+	v := int(math.Round(value))
+
+	if v < p.Min {
+		return p.Min
+	}
+
+	if v > p.Max {
+		return p.Max
+	}
+
+	return v
+}
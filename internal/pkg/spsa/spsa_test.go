@@ -0,0 +1,121 @@
+package spsa
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type params_io struct {
+	in    string
+	out   []Param
+	valid bool
+}
+
+var paramLists = []params_io{
+	{"Aggression 50 0 100 5", []Param{{Name: "Aggression", Value: 50, Min: 0, Max: 100, Step: 5, Rate: DefaultRate}}, true},
+	{"# comment\n\nA, 1, 0, 10, 1, 0.01\nB 2 0 4 0.5", []Param{{Name: "A", Value: 1, Min: 0, Max: 10, Step: 1, Rate: 0.01}, {Name: "B", Value: 2, Min: 0, Max: 4, Step: 0.5, Rate: DefaultRate}}, true},
+	{"A 50 0 10 1", nil, false},
+	{"A 5 0 10 0", nil, false},
+	{"A 5 0 10", nil, false},
+	{"A five 0 10 1", nil, false},
+	{"# nothing", nil, false},
+}
+
+func TestParseParams(t *testing.T) {
+	for _, io := range paramLists {
+		params, err := ParseParams(strings.NewReader(io.in))
+
+		if !io.valid {
+			if err == nil {
+				t.Errorf("Expected %q to be invalid", io.in)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Expected %q to be valid, got %v", io.in, err)
+			continue
+		}
+
+		if len(params) != len(io.out) {
+			t.Errorf("Expected %d parameters, got %d", len(io.out), len(params))
+			continue
+		}
+
+		for idx, p := range params {
+			if p != io.out[idx] {
+				t.Errorf("Expected %v, got %v", io.out[idx], p)
+			}
+		}
+	}
+}
+
+func TestPerturbAndUpdate(t *testing.T) {
+	tuner := New([]Param{{Name: "A", Value: 50, Min: 0, Max: 100, Step: 4, Rate: DefaultRate}}, 100)
+	rng := rand.New(rand.NewSource(1))
+	p := tuner.Perturb(99, rng)
+
+	if math.Abs(p.Step[0]-4) > 1e-9 {
+		t.Errorf("Expected the last step to be 4, got %f", p.Step[0])
+	}
+
+	if p.Plus[0]-50 != int(4*p.Delta[0]) || 50-p.Minus[0] != int(4*p.Delta[0]) {
+		t.Errorf("Expected values around 50, got %d and %d", p.Plus[0], p.Minus[0])
+	}
+
+	tuner.Update(p, 2)
+	expected := 50 + DefaultRate*4*2*p.Delta[0]
+
+	if math.Abs(tuner.Params[0].Value-expected) > 1e-9 {
+		t.Errorf("Expected %f, got %f", expected, tuner.Params[0].Value)
+	}
+
+	if tuner.Iteration != 1 {
+		t.Errorf("Expected one finished iteration, got %d", tuner.Iteration)
+	}
+
+	first := tuner.Perturb(0, rng)
+
+	if first.Step[0] <= p.Step[0] {
+		t.Errorf("Expected the first step %f to be larger than the last step %f", first.Step[0], p.Step[0])
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tuner := New([]Param{{Name: "A", Value: 1, Min: 0, Max: 2, Step: 5, Rate: 1}}, 10)
+	p := tuner.Perturb(0, rand.New(rand.NewSource(1)))
+
+	if p.Plus[0] < 0 || p.Plus[0] > 2 || p.Minus[0] < 0 || p.Minus[0] > 2 {
+		t.Errorf("Expected values within the range, got %d and %d", p.Plus[0], p.Minus[0])
+	}
+
+	tuner.Update(p, 2)
+
+	if v := tuner.Params[0].Value; v < 0 || v > 2 {
+		t.Errorf("Expected value within the range, got %f", v)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tune.json")
+	tuner := New([]Param{{Name: "A", Value: 12.5, Min: 0, Max: 100, Step: 2, Rate: DefaultRate}}, 10)
+	tuner.Iteration = 3
+
+	if err := tuner.Save(path); err != nil {
+		t.Fatalf("Expected checkpoint to be saved, got %v", err)
+	}
+
+	loaded, err := Load(path)
+
+	if err != nil {
+		t.Fatalf("Expected checkpoint to load, got %v", err)
+	}
+
+	if loaded.Iteration != 3 || loaded.Iterations != 10 || loaded.Params[0] != tuner.Params[0] {
+		t.Errorf("Expected %v, got %v", tuner, loaded)
+	}
+}
@@ -0,0 +1,222 @@
+// Package stats provides the statistics which are used to compare two engines
+// based on the results of the games they played against each other.
+// All results are counted from the perspective of the first engine.
+package stats
+
+import (
+	"math"
+)
+
+// Trinomial counts the wins, draws and losses of single games.
+type Trinomial struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// Pentanomial counts game pairs by the score of the pair.
+// The index is the score of the pair in half points, i.e. index 0 counts
+// pairs with two losses and index 4 counts pairs with two wins.
+type Pentanomial [5]int
+
+// Estimate is an Elo difference with the bounds of its 95% confidence interval.
+// The scores are kept within minScore of 0 and 1, so the estimate and its bounds
+// are finite even for perfect scores.
+type Estimate struct {
+	Elo   float64 // The estimated Elo difference
+	Lower float64 // The lower bound of the confidence interval
+	Upper float64 // The upper bound of the confidence interval
+}
+
+// SPRT configures a sequential probability ratio test.
+// The test decides between the hypothesis H0 that the Elo difference is
+// Elo0 and the hypothesis H1 that the Elo difference is Elo1.
+type SPRT struct {
+	Elo0  float64 // The Elo difference of H0
+	Elo1  float64 // The Elo difference of H1
+	Alpha float64 // The probability to accept H1 although H0 is true
+	Beta  float64 // The probability to accept H0 although H1 is true
+}
+
+// Decision is the state of a sequential probability ratio test.
+type Decision int
+
+const (
+	Continue Decision = iota // More games are required
+	AcceptH0                 // The test stops and accepts H0
+	AcceptH1                 // The test stops and accepts H1
+)
+
+// z95 is the quantile of the standard normal distribution for a two-sided 95% interval.
+const z95 = 1.959963984540054
+
+// minScore is the distance to 0 and 1 scores are clamped to when they are converted
+// into an estimate. It limits the estimated Elo difference to about ±1200.
+const minScore = 0.001
+
+// Add counts the score of a single game which is 0, 0.5 or 1.
+func (t *Trinomial) Add(score float64) {
+	switch {
+	case score > 0.5:
+		t.Wins++
+	case score < 0.5:
+		t.Losses++
+	default:
+		t.Draws++
+	}
+}
+
+// Games returns the number of counted games.
+func (t Trinomial) Games() int {
+	return t.Wins + t.Draws + t.Losses
+}
+
+// Score returns the average score per game.
+func (t Trinomial) Score() float64 {
+	mean, _ := t.distribution()
+	return mean
+}
+
+// Elo returns the estimated Elo difference.
+func (t Trinomial) Elo() Estimate {
+	mean, variance := t.distribution()
+	return estimate(mean, variance, t.Games())
+}
+
+// LOS returns the likelihood of superiority, which is the probability that
+// the first engine is stronger than the second one. Draws are ignored.
+func (t Trinomial) LOS() float64 {
+	if t.Wins+t.Losses == 0 {
+		return 0.5
+	}
+
+	return 0.5 * (1 + math.Erf(float64(t.Wins-t.Losses)/math.Sqrt(2*float64(t.Wins+t.Losses))))
+}
+
+func (t Trinomial) distribution() (float64, float64) {
+	return distribution([]float64{0, 0.5, 1}, []int{t.Losses, t.Draws, t.Wins})
+}
+
+// Add counts a game pair with the given score between 0 and 2.
+func (p *Pentanomial) Add(score float64) {
+	idx := int(math.Round(score * 2))
+
+	if idx >= 0 && idx < len(p) {
+		p[idx]++
+	}
+}
+
+// Pairs returns the number of counted game pairs.
+func (p Pentanomial) Pairs() int {
+	n := 0
+
+	for _, c := range p {
+		n += c
+	}
+
+	return n
+}
+
+// Elo returns the estimated Elo difference.
+// The confidence interval accounts for the correlation of the games of a pair.
+func (p Pentanomial) Elo() Estimate {
+	mean, variance := p.distribution()
+	return estimate(mean, variance, p.Pairs())
+}
+
+func (p Pentanomial) distribution() (float64, float64) {
+	return distribution([]float64{0, 0.25, 0.5, 0.75, 1}, p[:])
+}
+
+// Bounds returns the lower and upper bound of the log-likelihood ratio.
+// H0 is accepted when the ratio falls below the lower bound and H1 is
+// accepted when it exceeds the upper bound.
+func (s SPRT) Bounds() (float64, float64) {
+	return math.Log(s.Beta / (1 - s.Alpha)), math.Log((1 - s.Beta) / s.Alpha)
+}
+
+// LLR returns the log-likelihood ratio of the game pairs.
+// It uses the normal approximation of the pentanomial model with logistic Elo.
+func (s SPRT) LLR(p Pentanomial) float64 {
+	mean, variance := p.distribution()
+
+	if variance <= 0 {
+		return 0
+	}
+
+	s0, s1 := score(s.Elo0), score(s.Elo1)
+
+	return float64(p.Pairs()) * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// Decide returns the state of the test for the given log-likelihood ratio.
+func (s SPRT) Decide(llr float64) Decision {
+	lower, upper := s.Bounds()
+
+	switch {
+	case llr <= lower:
+		return AcceptH0
+	case llr >= upper:
+		return AcceptH1
+	default:
+		return Continue
+	}
+}
+
+// Elo converts an average score between 0 and 1 into an Elo difference.
+func Elo(score float64) float64 {
+	if score <= 0 {
+		return math.Inf(-1)
+	}
+
+	if score >= 1 {
+		return math.Inf(1)
+	}
+
+	return 400 * math.Log10(score/(1-score))
+}
+
+// score converts an Elo difference into the expected average score.
+func score(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+func estimate(mean float64, variance float64, n int) Estimate {
+	if n == 0 {
+		return Estimate{}
+	}
+
+	margin := z95 * math.Sqrt(variance/float64(n))
+	clamp := func(score float64) float64 {
+		return math.Min(math.Max(score, minScore), 1-minScore)
+	}
+
+	return Estimate{
+		Elo:   Elo(clamp(mean)),
+		Lower: Elo(clamp(mean - margin)),
+		Upper: Elo(clamp(mean + margin)),
+	}
+}
+
+func distribution(values []float64, counts []int) (float64, float64) {
+	n := 0
+	sum := 0.0
+	variance := 0.0
+
+	for idx, c := range counts {
+		n += c
+		sum += values[idx] * float64(c)
+	}
+
+	if n == 0 {
+		return 0.5, 0
+	}
+
+	mean := sum / float64(n)
+
+	for idx, c := range counts {
+		variance += float64(c) * (values[idx] - mean) * (values[idx] - mean)
+	}
+
+	return mean, variance / float64(n)
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a float64, b float64, eps float64) bool {
+	return math.Abs(a-b) < eps
+}
+
+func TestElo(t *testing.T) {
+	if e := Elo(0.5); e != 0 {
+		t.Errorf("Expected 0, got %f", e)
+	}
+
+	if e := Elo(0.75); !near(e, 190.85, 0.01) {
+		t.Errorf("Expected 190.85, got %f", e)
+	}
+
+	if e := Elo(0.25); !near(e, -190.85, 0.01) {
+		t.Errorf("Expected -190.85, got %f", e)
+	}
+
+	if !math.IsInf(Elo(1), 1) || !math.IsInf(Elo(0), -1) {
+		t.Errorf("Expected infinite Elo for perfect scores")
+	}
+}
+
+func TestTrinomial(t *testing.T) {
+	tri := Trinomial{Wins: 60, Draws: 20, Losses: 20}
+	est := tri.Elo()
+
+	if !near(tri.Score(), 0.7, 1e-9) {
+		t.Errorf("Expected score of 0.7, got %f", tri.Score())
+	}
+
+	if !near(est.Elo, 147.19, 0.01) || est.Lower >= est.Elo || est.Upper <= est.Elo {
+		t.Errorf("Expected 147.19 within its bounds, got %v", est)
+	}
+
+	if los := tri.LOS(); !near(los, 0.99999, 0.0001) {
+		t.Errorf("Expected a LOS close to 1, got %f", los)
+	}
+
+	if los := (Trinomial{Wins: 5, Draws: 3, Losses: 5}).LOS(); los != 0.5 {
+		t.Errorf("Expected a LOS of 0.5, got %f", los)
+	}
+
+	for _, perfect := range []Estimate{(Trinomial{Wins: 2}).Elo(), (Trinomial{Losses: 2}).Elo(), (Pentanomial{0, 0, 0, 0, 1}).Elo()} {
+		for _, e := range []float64{perfect.Elo, perfect.Lower, perfect.Upper} {
+			if math.IsInf(e, 0) || math.IsNaN(e) {
+				t.Errorf("Expected a finite estimate for a perfect score, got %v", perfect)
+			}
+		}
+	}
+
+	if est := (Trinomial{Wins: 2, Losses: 1}).Elo(); math.IsInf(est.Upper, 0) || est.Upper <= est.Elo {
+		t.Errorf("Expected a finite upper bound above the estimate, got %v", est)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	sprt := SPRT{Elo0: 0, Elo1: 5, Alpha: 0.05, Beta: 0.05}
+	lower, upper := sprt.Bounds()
+
+	if !near(lower, -2.944, 0.001) || !near(upper, 2.944, 0.001) {
+		t.Errorf("Expected bounds of -2.944 and 2.944, got %f and %f", lower, upper)
+	}
+
+	if llr := sprt.LLR(Pentanomial{}); llr != 0 {
+		t.Errorf("Expected LLR of 0 without games, got %f", llr)
+	}
+
+	strong := Pentanomial{10, 200, 500, 300, 40}
+	weak := Pentanomial{40, 300, 500, 200, 10}
+
+	if d := sprt.Decide(sprt.LLR(strong)); d != AcceptH1 {
+		t.Errorf("Expected H1 to be accepted, got %d with LLR %f", d, sprt.LLR(strong))
+	}
+
+	if d := sprt.Decide(sprt.LLR(weak)); d != AcceptH0 {
+		t.Errorf("Expected H0 to be accepted, got %d with LLR %f", d, sprt.LLR(weak))
+	}
+
+	if d := sprt.Decide(sprt.LLR(Pentanomial{1, 2, 3, 2, 1})); d != Continue {
+		t.Errorf("Expected the test to continue")
+	}
+}
@@ -0,0 +1,171 @@
+package sys
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Core is a physical core with the logical CPUs it provides.
+// A core with simultaneous multithreading (SMT) has more than one logical CPU.
+type Core struct {
+	Node int   // The NUMA node of the core.
+	CPUs []int // The logical CPUs of the core.
+}
+
+// PartitionCPUs returns slots disjoint sets of logical CPUs with size CPUs each.
+// Sets are filled with one CPU per physical core, so games do not share the
+// execution units of SMT siblings, and prefer CPUs of a single NUMA node.
+// If there are not enough physical cores, whole cores including their SMT siblings
+// are assigned, so siblings are never split between sets.
+// If the CPUs are not sufficient for all sets, nil is returned.
+func PartitionCPUs(cores []Core, slots int, size int) [][]int {
+	if slots < 1 || size < 1 {
+		return nil
+	}
+
+	nodes := groupByNode(cores)
+
+	return assign(nodes, slots, size, len(cores) < slots*size)
+}
+
+// assign fills the sets with the CPUs of the cores of the nodes.
+// If whole is false only the first CPU of each core is used, otherwise all SMT siblings.
+// Each set is taken from the smallest node which can hold the complete set,
+// so a set only spans several nodes if no single node has enough free CPUs.
+func assign(nodes [][]Core, slots int, size int, whole bool) [][]int {
+	sets := make([][]int, 0, slots)
+
+	for len(sets) < slots {
+		set := make([]int, 0, size)
+		idx := bestNode(nodes, size, whole)
+
+		for len(set) < size {
+			if idx < 0 || len(nodes[idx]) == 0 {
+				idx = largestNode(nodes)
+			}
+
+			if idx < 0 {
+				return nil
+			}
+
+			core := nodes[idx][0]
+			nodes[idx] = nodes[idx][1:]
+
+			if whole {
+				set = append(set, core.CPUs...)
+			} else {
+				set = append(set, core.CPUs[0])
+			}
+		}
+
+		sort.Ints(set)
+		sets = append(sets, set)
+	}
+
+	return sets
+}
+
+// bestNode returns the index of the node with the fewest free cores which can
+// still hold a set of the given size or -1 if there is no such node.
+func bestNode(nodes [][]Core, size int, whole bool) int {
+	res := -1
+
+	for idx, node := range nodes {
+		free := len(node)
+
+		if whole {
+			free = 0
+
+			for _, core := range node {
+				free += len(core.CPUs)
+			}
+		}
+
+		if free >= size && (res < 0 || len(node) < len(nodes[res])) {
+			res = idx
+		}
+	}
+
+	return res
+}
+
+// largestNode returns the index of the node with the most free cores or -1 if all nodes are empty.
+func largestNode(nodes [][]Core) int {
+	res := -1
+
+	for idx, node := range nodes {
+		if len(node) > 0 && (res < 0 || len(node) > len(nodes[res])) {
+			res = idx
+		}
+	}
+
+	return res
+}
+
+// groupByNode returns the cores grouped by their NUMA node, ordered by node and first CPU.
+func groupByNode(cores []Core) [][]Core {
+	byNode := make(map[int][]Core)
+	ids := make([]int, 0)
+
+	for _, core := range cores {
+		if len(core.CPUs) == 0 {
+			continue
+		}
+
+		if _, ok := byNode[core.Node]; !ok {
+			ids = append(ids, core.Node)
+		}
+
+		byNode[core.Node] = append(byNode[core.Node], core)
+	}
+
+	sort.Ints(ids)
+	nodes := make([][]Core, 0, len(ids))
+
+	for _, id := range ids {
+		node := byNode[id]
+
+		sort.Slice(node, func(i, j int) bool {
+			return node[i].CPUs[0] < node[j].CPUs[0]
+		})
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+// parseCPUList parses a list of CPUs in the kernel format, e.g. "0-3,8,10-11".
+func parseCPUList(list string) ([]int, error) {
+	cpus := make([]int, 0)
+	list = strings.TrimSpace(list)
+
+	if list == "" {
+		return cpus, nil
+	}
+
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+
+		if err != nil {
+			return nil, errors.New("Invalid CPU list: " + list)
+		}
+
+		end := start
+
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, errors.New("Invalid CPU list: " + list)
+			}
+		}
+
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	return cpus, nil
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+package sys
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// CPUTopology returns the physical cores of the online CPUs with their SMT siblings and NUMA nodes.
+// The topology is read from sysfs. Only the CPUs the process may run on are included,
+// e.g. within a container or a taskset.
+func CPUTopology() ([]Core, error) {
+	var allowed unix.CPUSet
+	online, err := os.ReadFile("/sys/devices/system/cpu/online")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unix.SchedGetaffinity(0, &allowed); err != nil {
+		return nil, err
+	}
+
+	cpus, err := parseCPUList(string(online))
+
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	cores := make([]Core, 0, len(cpus))
+
+	for _, cpu := range cpus {
+		if !allowed.IsSet(cpu) {
+			continue
+		}
+
+		dir := "/sys/devices/system/cpu/cpu" + strconv.Itoa(cpu)
+		list, err := os.ReadFile(dir + "/topology/thread_siblings_list")
+
+		if err != nil {
+			cores = append(cores, Core{Node: cpuNode(dir), CPUs: []int{cpu}})
+			continue
+		}
+
+		key := strings.TrimSpace(string(list))
+
+		if seen[key] {
+			continue
+		}
+
+		siblings, err := parseCPUList(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		usable := make([]int, 0, len(siblings))
+
+		for _, sibling := range siblings {
+			if allowed.IsSet(sibling) {
+				usable = append(usable, sibling)
+			}
+		}
+
+		seen[key] = true
+		cores = append(cores, Core{Node: cpuNode(dir), CPUs: usable})
+	}
+
+	return cores, nil
+}
+
+// SetAffinity pins all threads of the process to the given CPUs.
+// Threads created later by the process inherit the affinity.
+func SetAffinity(pid int, cpus []int) error {
+	var set unix.CPUSet
+
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+
+	tasks, err := os.ReadDir("/proc/" + strconv.Itoa(pid) + "/task")
+
+	if err != nil {
+		return unix.SchedSetaffinity(pid, &set)
+	}
+
+	for _, task := range tasks {
+		if tid, err := strconv.Atoi(task.Name()); err == nil {
+			if err := unix.SchedSetaffinity(tid, &set); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// cpuNode returns the NUMA node of the CPU with the given sysfs directory.
+// Without NUMA information all CPUs belong to node 0.
+func cpuNode(dir string) int {
+	matches, _ := filepath.Glob(dir + "/node[0-9]*")
+
+	for _, match := range matches {
+		if node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(match), "node")); err == nil {
+			return node
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package sys
+
+import (
+	"errors"
+)
+
+// CPUTopology returns the physical cores of the online CPUs with their SMT siblings and NUMA nodes.
+// It is only supported on Linux.
+func CPUTopology() ([]Core, error) {
+	return nil, errors.New("CPU topology is only supported on Linux")
+}
+
+// SetAffinity pins all threads of the process to the given CPUs.
+// It is only supported on Linux.
+func SetAffinity(pid int, cpus []int) error {
+	return errors.New("CPU affinity is only supported on Linux")
+}
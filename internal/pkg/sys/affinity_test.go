@@ -0,0 +1,70 @@
+package sys
+
+import (
+	"reflect"
+	"testing"
+)
+
+type partition_io struct {
+	cores []Core
+	slots int
+	size  int
+	out   [][]int
+}
+
+// smt has four cores with two logical CPUs each on a single node.
+var smt = []Core{
+	{Node: 0, CPUs: []int{0, 4}},
+	{Node: 0, CPUs: []int{1, 5}},
+	{Node: 0, CPUs: []int{2, 6}},
+	{Node: 0, CPUs: []int{3, 7}},
+}
+
+// numa has two nodes with three cores each.
+var numa = []Core{
+	{Node: 0, CPUs: []int{0}},
+	{Node: 0, CPUs: []int{1}},
+	{Node: 0, CPUs: []int{2}},
+	{Node: 1, CPUs: []int{3}},
+	{Node: 1, CPUs: []int{4}},
+	{Node: 1, CPUs: []int{5}},
+}
+
+var partitions = []partition_io{
+	{smt, 2, 2, [][]int{{0, 1}, {2, 3}}},
+	{smt, 4, 1, [][]int{{0}, {1}, {2}, {3}}},
+	{smt, 2, 4, [][]int{{0, 1, 4, 5}, {2, 3, 6, 7}}},
+	{smt, 8, 1, nil},
+	{numa, 2, 2, [][]int{{0, 1}, {3, 4}}},
+	{numa, 3, 2, [][]int{{0, 1}, {3, 4}, {2, 5}}},
+	{numa, 1, 6, [][]int{{0, 1, 2, 3, 4, 5}}},
+	{numa, 2, 4, nil},
+}
+
+func TestPartitionCPUs(t *testing.T) {
+	for _, io := range partitions {
+		out := PartitionCPUs(io.cores, io.slots, io.size)
+
+		if len(io.out) == 0 && len(out) == 0 {
+			continue
+		}
+
+		if !reflect.DeepEqual(out, io.out) {
+			t.Errorf("Expected %v for %d sets of %d CPUs, got %v", io.out, io.slots, io.size, out)
+		}
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,8,10-11\n")
+
+	if err != nil || !reflect.DeepEqual(cpus, []int{0, 1, 2, 3, 8, 10, 11}) {
+		t.Errorf("Expected [0 1 2 3 8 10 11], got %v (%v)", cpus, err)
+	}
+
+	for _, list := range []string{"a", "3-1", "1-"} {
+		if _, err := parseCPUList(list); err == nil {
+			t.Errorf("Expected %q to be invalid", list)
+		}
+	}
+}
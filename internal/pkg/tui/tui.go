@@ -0,0 +1,75 @@
+// Package tui provides the styles and helpers shared by the terminal user interfaces.
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TickMsg is sent periodically by Tick.
+type TickMsg time.Time
+
+// Box returns the style of a panel with a rounded border.
+// A new style is returned on each call, so it can be modified freely.
+func Box() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("241")).
+		Padding(0, 1)
+}
+
+// Title returns the style of a title.
+func Title() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#B949FF")).
+		Bold(true)
+}
+
+// Label returns the style of a label in front of a value.
+func Label() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Bold(true)
+}
+
+// Error returns the style of an error message.
+func Error() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("9")).
+		Bold(true)
+}
+
+// Tick creates a command that sends a TickMsg after each interval.
+func Tick(interval time.Duration) tea.Cmd {
+	return tea.Every(interval, func(t time.Time) tea.Msg {
+		return TickMsg(t)
+	})
+}
+
+// FormatClock returns the time in ms as minutes and seconds, e.g. "4:05".
+// Tenths of a second are shown below ten seconds.
+func FormatClock(ms int) string {
+	if ms < 0 {
+		ms = 0
+	}
+
+	if ms < 10000 {
+		return fmt.Sprintf("0:%04.1f", float64(ms)/1000)
+	}
+
+	return fmt.Sprintf("%d:%02d", ms/60000, ms/1000%60)
+}
+
+// FormatCount returns the number with a k or M suffix for large values, e.g. "1.2M".
+func FormatCount(n float64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", n/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fk", n/1e3)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"testing"
+)
+
+type clock_io struct {
+	ms  int
+	out string
+}
+
+type count_io struct {
+	n   float64
+	out string
+}
+
+var clocks = []clock_io{
+	{-5, "0:00.0"},
+	{9940, "0:09.9"},
+	{10000, "0:10"},
+	{245000, "4:05"},
+	{3600000, "60:00"},
+}
+
+var counts = []count_io{
+	{0, "0"},
+	{999, "999"},
+	{1250, "1.2k"},
+	{2500000, "2.5M"},
+}
+
+func TestFormatClock(t *testing.T) {
+	for _, io := range clocks {
+		if out := FormatClock(io.ms); out != io.out {
+			t.Errorf("Expected %s for %d ms, got %s", io.out, io.ms, out)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for _, io := range counts {
+		if out := FormatCount(io.n); out != io.out {
+			t.Errorf("Expected %s for %g, got %s", io.out, io.n, out)
+		}
+	}
+}
@@ -1,18 +1,46 @@
 package uci
 
 import (
-	"fmt"
 	"strings"
+	"time"
 )
 
 // Setup sends the uci command to the engine and waits for the uciok response.
 // It also parses the response for option configurations and saves them in
 // the UCI struct.
 func (u *UCI) Setup() {
-	u.header = u.engine.Line()
+	u.SetupTimeout(0)
+}
+
+// SetupTimeout is equal to Setup but gives up if the engine did not send its
+// header and uciok within the timeout. It returns false if the engine did not
+// respond in time or exited. A timeout of zero waits forever.
+func (u *UCI) SetupTimeout(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	remaining := func() time.Duration {
+		if timeout == 0 {
+			return 0
+		}
+
+		if left := time.Until(deadline); left > 0 {
+			return left
+		}
+
+		return time.Nanosecond
+	}
+
+	header := u.engine.ReadTimeout(func(line string) bool {
+		u.header = line
+		return true
+	}, remaining())
+
+	if !header {
+		return false
+	}
+
 	u.engine.Send("uci")
 
-	u.engine.Read(func(line string) bool {
+	return u.engine.ReadTimeout(func(line string) bool {
 		if strings.HasPrefix(line, "option") {
 			opt, e := parseOptionConfigStr(line)
 
@@ -22,7 +50,7 @@ func (u *UCI) Setup() {
 		}
 
 		return line == "uciok"
-	})
+	}, remaining())
 }
 
 // Start sends the ucinewgame command to the engine.
@@ -64,25 +92,70 @@ func (u *UCI) SetMoves(moves ...string) {
 // to a MoveInfo struct containing the information about the move.
 // The function blocks until the engine has found a move.
 func (u *UCI) GetMove(ms int) *MoveInfo {
-	cmd := "go movetime " + fmt.Sprintf("%d", ms)
+	return u.Search(Limits{MoveTime: ms})
+}
+
+// Search sends the go command to the engine with the given search limits.
+// The function returns a pointer to a MoveInfo struct containing the
+// information about the move.
+// The function blocks until the engine has found a move.
+func (u *UCI) Search(limits Limits) *MoveInfo {
+	return u.SearchTimeout(limits, 0)
+}
+
+// SearchTimeout is equal to Search but gives up if the engine did not
+// find a move within the timeout. A timeout of zero waits forever.
+// If the engine timed out or exited, nil is returned.
+func (u *UCI) SearchTimeout(limits Limits, timeout time.Duration) *MoveInfo {
+	return u.search(limits, timeout, false)
+}
+
+// SearchTrace is equal to SearchTimeout but keeps every info line the engine
+// sent about the progress of the search, e.g. one per finished depth, in the trace
+// of the returned MoveInfo. Lines which only report the current move or a string are omitted.
+func (u *UCI) SearchTrace(limits Limits, timeout time.Duration) *MoveInfo {
+	return u.search(limits, timeout, true)
+}
+
+func (u *UCI) search(limits Limits, timeout time.Duration, trace bool) *MoveInfo {
 	var info *MoveInfo
 	var last string
+	var steps []MoveInfo
 
-	u.engine.Scan(cmd, func(line string) bool {
+	u.engine.Send(limits.String())
+	u.engine.ReadTimeout(func(line string) bool {
 		if strings.HasPrefix(line, "bestmove") {
 			move := strings.Split(line, " ")[1]
 			info = parseInfoStr(last)
 			info.Move = move
+			info.Trace = steps
 			return true
 		}
 
+		if trace && strings.HasPrefix(line, "info") {
+			if step := parseInfoStr(line); step.Depth > 0 && step.CurrentMove == "" {
+				steps = append(steps, *step)
+			}
+		}
+
 		last = line
 		return false
-	})
+	}, timeout)
 
 	return info
 }
 
+// WaitReady sends the isready command to the engine and waits for the
+// readyok response. It returns false if the engine did not respond within the
+// timeout or exited.
+func (u *UCI) WaitReady(timeout time.Duration) bool {
+	u.engine.Send("isready")
+
+	return u.engine.ReadTimeout(func(line string) bool {
+		return line == "readyok"
+	}, timeout)
+}
+
 // IsEngineReady sends the isready command to the engine and returns true if
 // the engine is ready.
 func (u *UCI) IsEngineReady() bool {
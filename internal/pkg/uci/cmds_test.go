@@ -0,0 +1,66 @@
+package uci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// script writes an executable shell script, which acts as engine, into a temporary directory.
+func script(t *testing.T, body string) string {
+	path := filepath.Join(t.TempDir(), "engine.sh")
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// awaitExit waits until the engine process exited and its output was released.
+func awaitExit(t *testing.T, u *UCI) {
+	deadline := time.Now().Add(2 * time.Second)
+
+	for !u.Exited() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the engine to exit after it was closed")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSetupTimeout(t *testing.T) {
+	u, err := NewFromExe(script(t, "echo Silent\nexec sleep 10"), nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	if u.SetupTimeout(200 * time.Millisecond) {
+		t.Errorf("Expected the setup of an engine without uciok to time out")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the setup to give up after the timeout, took %v", elapsed)
+	}
+
+	u.Close()
+	awaitExit(t, u)
+}
+
+func TestCloseUnread(t *testing.T) {
+	u, err := NewFromExe(script(t, "exec yes info depth 1"), nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	u.Close()
+	u.Quit()
+	awaitExit(t, u)
+}
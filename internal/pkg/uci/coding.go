@@ -88,6 +88,16 @@ func parseOptionConfigStr(info string) (OptionConfig, error) {
 	return res, nil
 }
 
+// ParseInfo parses an info line the engine sent during a search.
+// It returns nil if the line is not an info line.
+func ParseInfo(line string) *MoveInfo {
+	if !strings.HasPrefix(line, "info ") {
+		return nil
+	}
+
+	return parseInfoStr(line)
+}
+
 func parseInfoStr(info string) *MoveInfo {
 	parts := strings.Split(info, " ")
 	res := MoveInfo{}
@@ -17,6 +17,25 @@ type infostr_io struct {
 	out MoveInfo
 }
 
+type validate_io struct {
+	config OptionConfig
+	value  string
+	valid  bool
+}
+
+var validations = []validate_io{
+	{OptionConfig{Name: "Hash", Type: Spin, Min: 1, Max: 1024}, "16", true},
+	{OptionConfig{Name: "Hash", Type: Spin, Min: 1, Max: 1024}, "0", false},
+	{OptionConfig{Name: "Hash", Type: Spin, Min: 1, Max: 1024}, "2048", false},
+	{OptionConfig{Name: "Hash", Type: Spin, Min: 1, Max: 1024}, "big", false},
+	{OptionConfig{Name: "Ponder", Type: Check}, "true", true},
+	{OptionConfig{Name: "Ponder", Type: Check}, "yes", false},
+	{OptionConfig{Name: "Style", Type: Combo, Var: []string{"Solid", "Normal"}}, "normal", true},
+	{OptionConfig{Name: "Style", Type: Combo, Var: []string{"Solid", "Normal"}}, "Risky", false},
+	{OptionConfig{Name: "EvalFile", Type: String}, "nn.nnue", true},
+	{OptionConfig{Name: "Clear Hash", Type: Button}, "", true},
+}
+
 var scores = []score_io{
 	{"score cp 100", Score{Type: CP, Value: 100, Lowerbound: false, Upperbound: false}, 3},
 	{"score cp 100 lowerbound", Score{Type: CP, Value: 100, Lowerbound: true, Upperbound: false}, 4},
@@ -88,3 +107,27 @@ func TestParseInfoString(t *testing.T) {
 		}
 	}
 }
+
+func TestParseInfo(t *testing.T) {
+	if info := ParseInfo("bestmove e2e4"); info != nil {
+		t.Errorf("Expected nil for a line which is not an info line, got %v", *info)
+	}
+
+	if info := ParseInfo("info depth 3 score cp 20 pv e2e4 e7e5"); info == nil || info.Depth != 3 || len(info.Pv) != 2 {
+		t.Errorf("Expected depth 3 and a pv with two moves, got %v", info)
+	}
+}
+
+func TestValidateOption(t *testing.T) {
+	for _, io := range validations {
+		err := io.config.Validate(io.value)
+
+		if io.valid && err != nil {
+			t.Errorf("Expected %s to be valid for %s, got %v", io.value, io.config.Name, err)
+		}
+
+		if !io.valid && err == nil {
+			t.Errorf("Expected %s to be invalid for %s", io.value, io.config.Name)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package uci
 
 import (
+	"errors"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/HenrikThoroe/ivy-adapter/internal/pkg/mgmt"
@@ -44,24 +46,38 @@ type Score struct {
 
 // MoveInfo is a wrapper for the information returned by the engine after a move.
 type MoveInfo struct {
-	Move              string   `json:"move,omitempty"`              // The move itself
-	Depth             int      `json:"depth,omitempty"`             // The depth the engine searched to
-	SelDepth          int      `json:"selDepth,omitempty"`          // The selective depth the engine searched to
-	Time              int      `json:"time,omitempty"`              // The time the engine searched in ms
-	Nodes             int      `json:"nodes,omitempty"`             // The number of nodes the engine searched
-	Pv                []string `json:"pv,omitempty"`                // The principal variation
-	MultiPv           int      `json:"multipv,omitempty"`           // The multipv number
-	Score             Score    `json:"score,omitempty"`             // The score of the move
-	CurrentMove       string   `json:"currentMove,omitempty"`       // The current move the engine is searching
-	CurrentMoveNumber int      `json:"currentMoveNumber,omitempty"` // The current move number
-	HashFull          int      `json:"hashFull,omitempty"`          // The number of hash entries the engine searched
-	Nps               int      `json:"nps,omitempty"`               // The number of nodes per second the engine searched
-	TbHits            int      `json:"tbhits,omitempty"`            // The number of tablebase hits
-	Sbhits            int      `json:"sbhits,omitempty"`            // The number of syzygy tablebase hits
-	CpuLoad           int      `json:"cpuload,omitempty"`           // The CPU load in percent
-	String            string   `json:"string,omitempty"`            // Additional information
-	Refutation        []string `json:"refutation,omitempty"`        // The refutation to the current move
-	Currline          []string `json:"currline,omitempty"`          // The current line the engine is searching
+	Move              string     `json:"move,omitempty"`              // The move itself
+	Depth             int        `json:"depth,omitempty"`             // The depth the engine searched to
+	SelDepth          int        `json:"selDepth,omitempty"`          // The selective depth the engine searched to
+	Time              int        `json:"time,omitempty"`              // The time the engine searched in ms
+	Nodes             int        `json:"nodes,omitempty"`             // The number of nodes the engine searched
+	Pv                []string   `json:"pv,omitempty"`                // The principal variation
+	MultiPv           int        `json:"multipv,omitempty"`           // The multipv number
+	Score             Score      `json:"score,omitempty"`             // The score of the move
+	CurrentMove       string     `json:"currentMove,omitempty"`       // The current move the engine is searching
+	CurrentMoveNumber int        `json:"currentMoveNumber,omitempty"` // The current move number
+	HashFull          int        `json:"hashFull,omitempty"`          // The number of hash entries the engine searched
+	Nps               int        `json:"nps,omitempty"`               // The number of nodes per second the engine searched
+	TbHits            int        `json:"tbhits,omitempty"`            // The number of tablebase hits
+	Sbhits            int        `json:"sbhits,omitempty"`            // The number of syzygy tablebase hits
+	CpuLoad           int        `json:"cpuload,omitempty"`           // The CPU load in percent
+	String            string     `json:"string,omitempty"`            // Additional information
+	Refutation        []string   `json:"refutation,omitempty"`        // The refutation to the current move
+	Currline          []string   `json:"currline,omitempty"`          // The current line the engine is searching
+	Trace             []MoveInfo `json:"trace,omitempty"`             // The progress of the search, if it was traced
+}
+
+// Limits restricts the search of the engine and is sent as part of the go command.
+// Limits with a zero value are omitted. The remaining times of both players
+// are sent as soon as one of them is set.
+type Limits struct {
+	MoveTime  int // The time to search in ms
+	Depth     int // The depth to search to
+	WTime     int // The remaining time of white in ms
+	BTime     int // The remaining time of black in ms
+	WInc      int // The increment of white in ms
+	BInc      int // The increment of black in ms
+	MovesToGo int // The number of moves until the next time control
 }
 
 // Option contains the name and value of an option, which can be set.
@@ -111,8 +127,23 @@ func NewFromExe(exe string, scb func(string), rcb func(string)) (*UCI, error) {
 	}, nil
 }
 
-// Close kills the engine process.
+// NewFromExePinned is equal to NewFromExe but pins the engine process to the given CPUs.
+func NewFromExePinned(exe string, cpus []int, scb func(string), rcb func(string)) (*UCI, error) {
+	conn, err := mgmt.LaunchPinnedEngine(exe, cpus, scb, rcb)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &UCI{
+		engine:  conn,
+		options: make([]OptionConfig, 0),
+	}, nil
+}
+
+// Close kills the engine process and stops the communication with it.
 func (uci *UCI) Close() {
+	uci.engine.Close()
 	proc, err := os.FindProcess(uci.engine.Pid)
 
 	if err != nil {
@@ -122,6 +153,11 @@ func (uci *UCI) Close() {
 	proc.Kill()
 }
 
+// Exited returns true if the engine process exited.
+func (uci *UCI) Exited() bool {
+	return uci.engine.Exited()
+}
+
 // GetOptionConfig returns the option configuration for the given option name.
 // If the option does not exist, nil is returned.
 // The option name is case insensitive.
@@ -135,6 +171,41 @@ func (u UCI) GetOptionConfig(name string) *OptionConfig {
 	return nil
 }
 
+// String returns the go command for the limits.
+func (l Limits) String() string {
+	cmd := "go"
+	add := func(key string, value int) {
+		cmd += " " + key + " " + strconv.Itoa(value)
+	}
+
+	if l.WTime > 0 || l.BTime > 0 {
+		add("wtime", l.WTime)
+		add("btime", l.BTime)
+	}
+
+	if l.WInc > 0 {
+		add("winc", l.WInc)
+	}
+
+	if l.BInc > 0 {
+		add("binc", l.BInc)
+	}
+
+	if l.MovesToGo > 0 {
+		add("movestogo", l.MovesToGo)
+	}
+
+	if l.Depth > 0 {
+		add("depth", l.Depth)
+	}
+
+	if l.MoveTime > 0 {
+		add("movetime", l.MoveTime)
+	}
+
+	return cmd
+}
+
 // String returns the string representation of an option as the command to send to the engine.
 func (o Option) String() string {
 	if o.Value == "" {
@@ -160,3 +231,35 @@ func (o OptionConfig) Response(value string) Option {
 		Value: value,
 	}
 }
+
+// Validate returns an error if the value is not accepted by the option.
+// Spin values have to be integers within the bounds, check values true or false
+// and combo values one of the predefined variants. Buttons and strings accept any value.
+func (o OptionConfig) Validate(value string) error {
+	switch o.Type {
+	case Spin:
+		v, err := strconv.Atoi(value)
+
+		if err != nil {
+			return errors.New("Invalid value for option " + o.Name + ": " + value + " is not an integer")
+		}
+
+		if v < o.Min || v > o.Max {
+			return errors.New("Invalid value for option " + o.Name + ": " + value + " is not within " + strconv.Itoa(o.Min) + " and " + strconv.Itoa(o.Max))
+		}
+	case Check:
+		if value != "true" && value != "false" {
+			return errors.New("Invalid value for option " + o.Name + ": " + value + " is not a boolean")
+		}
+	case Combo:
+		for _, v := range o.Var {
+			if strings.EqualFold(v, value) {
+				return nil
+			}
+		}
+
+		return errors.New("Invalid value for option " + o.Name + ": " + value + " is not one of " + strings.Join(o.Var, ", "))
+	}
+
+	return nil
+}